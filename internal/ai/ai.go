@@ -0,0 +1,322 @@
+// Package ai decides what non player units do on their phase. It only sees a
+// Board built from the game state so decisions can be tested on fixed maps
+// without ebiten.
+package ai
+
+import (
+	"fmt"
+	"math"
+
+	"openFE/internal/combat"
+	"openFE/internal/pathfind"
+)
+
+type Behavior int
+
+const (
+	AGGRESSIVE Behavior = iota // Attacks the best target it can reach, otherwise walks toward the closest enemy
+	HOLD                       // Never moves, attacks whatever is in range of its tile
+	INRANGE                    // Attacks if something is reachable this turn, otherwise stays put
+	GUARD                      // Only attacks enemies near its guard tile, otherwise walks back to it
+	RETREAT                    // Aggressive until its HP is low, then runs away from enemies
+)
+
+// Names used for behaviors in data files
+var behaviorNames = map[string]Behavior{
+	"aggressive": AGGRESSIVE,
+	"hold":       HOLD,
+	"inRange":    INRANGE,
+	"guard":      GUARD,
+	"retreat":    RETREAT,
+}
+
+func ParseBehavior(name string) (Behavior, error) {
+	behavior, ok := behaviorNames[name]
+	if !ok {
+		return AGGRESSIVE, fmt.Errorf("unknown behavior %q", name)
+	}
+	return behavior, nil
+}
+
+const (
+	NoTarget         = -1
+	RetreatHPPercent = 30 // RETREAT units run at or below this much HP
+	killWeight       = 1000
+)
+
+type Unit struct {
+	ID        int
+	Faction   int
+	Move      int
+	MaxHP     int
+	Behavior  Behavior
+	GuardPos  [2]int
+	Combatant combat.Combatant // Pos and HP live here
+}
+
+type Board struct {
+	Width  int
+	Height int
+	Units  []Unit
+	// Cost for unit id to step onto pos, below 0 if it can't
+	MoveCost func(id int, pos [2]int) int
+	// Whether two factions fight on the same side, nil means only the same faction
+	IsAlly func(a, b int) bool
+	// Terrain bonus of pos, nil means no bonuses anywhere
+	Terrain func(pos [2]int) combat.TerrainBonus
+	// Weapon triangle of the exchanges, nil means every weapon is neutral
+	Triangle combat.TriangleMatrix
+}
+
+// Action is what a player would do with the unit: move it, then attack or wait
+type Action struct {
+	UnitID   int
+	MoveTo   [2]int
+	TargetID int // NoTarget to wait
+}
+
+func (b *Board) unit(id int) Unit {
+	for _, u := range b.Units {
+		if u.ID == id {
+			return u
+		}
+	}
+	panic("ai: unknown unit")
+}
+
+func (b *Board) allied(a, c Unit) bool {
+	if b.IsAlly == nil {
+		return a.Faction == c.Faction
+	}
+	return b.IsAlly(a.Faction, c.Faction)
+}
+
+func (b *Board) enemies(u Unit) []Unit {
+	enemies := []Unit{}
+	for _, other := range b.Units {
+		if other.Combatant.HP > 0 && !b.allied(u, other) {
+			enemies = append(enemies, other)
+		}
+	}
+	return enemies
+}
+
+func (b *Board) terrainBonus(pos [2]int) combat.TerrainBonus {
+	if b.Terrain == nil {
+		return combat.TerrainBonus{}
+	}
+	return b.Terrain(pos)
+}
+
+func (b *Board) terrain(u Unit) pathfind.Cost {
+	return func(pos [2]int) int {
+		return b.MoveCost(u.ID, pos)
+	}
+}
+
+// Tiles u can end its move on, same rules as a player's unit
+func (b *Board) Moves(u Unit) [][2]int {
+	occupant := func(pos [2]int) pathfind.Occupant {
+		for _, other := range b.Units {
+			if other.Combatant.HP <= 0 || other.Combatant.Pos != pos {
+				continue
+			}
+			if other.ID == u.ID {
+				return pathfind.SELF
+			}
+			if b.allied(u, other) {
+				return pathfind.ALLY
+			}
+			return pathfind.ENEMY
+		}
+		return pathfind.EMPTY
+	}
+	_, moves := pathfind.Moves(u.Combatant.Pos, b.Width, b.Height, u.Move, b.terrain(u), occupant)
+	return moves
+}
+
+// Decide picks the move and action for one unit
+func Decide(b *Board, id int) Action {
+	u := b.unit(id)
+	wait := Action{UnitID: id, MoveTo: u.Combatant.Pos, TargetID: NoTarget}
+
+	switch u.Behavior {
+	case HOLD:
+		if attack, ok := bestAttack(b, u, [][2]int{u.Combatant.Pos}, b.enemies(u)); ok {
+			return attack
+		}
+		return wait
+
+	case INRANGE:
+		if attack, ok := bestAttack(b, u, b.Moves(u), b.enemies(u)); ok {
+			return attack
+		}
+		return wait
+
+	case GUARD:
+		// Only go after enemies that could be hit from the guard tile's reach
+		reach := u.Move + u.Combatant.Weapon.MaxRange
+		near := []Unit{}
+		for _, e := range b.enemies(u) {
+			if combat.Distance(u.GuardPos, e.Combatant.Pos) <= reach {
+				near = append(near, e)
+			}
+		}
+		if attack, ok := bestAttack(b, u, b.Moves(u), near); ok {
+			return attack
+		}
+		return moveToward(b, u, [][2]int{u.GuardPos})
+
+	case RETREAT:
+		if u.MaxHP > 0 && u.Combatant.HP*100 <= u.MaxHP*RetreatHPPercent {
+			return retreat(b, u)
+		}
+	}
+
+	// AGGRESSIVE and healthy RETREAT units
+	if attack, ok := bestAttack(b, u, b.Moves(u), b.enemies(u)); ok {
+		return attack
+	}
+	targets := [][2]int{}
+	for _, e := range b.enemies(u) {
+		targets = append(targets, e.Combatant.Pos)
+	}
+	return moveToward(b, u, targets)
+}
+
+// Tries every target from every tile and keeps the best score. Ties go to the
+// earliest option, moves are sorted cheapest first so the unit doesn't wander.
+func bestAttack(b *Board, u Unit, moves [][2]int, enemies []Unit) (Action, bool) {
+	best := Action{}
+	bestScore := math.Inf(-1)
+	found := false
+	for _, pos := range moves {
+		attacker := u.Combatant
+		attacker.Pos = pos
+		attacker.Terrain = b.terrainBonus(pos)
+		for _, e := range enemies {
+			if !combat.CanAttack(attacker, e.Combatant) {
+				continue
+			}
+			score := Score(combat.Face(attacker, e.Combatant, b.Triangle))
+			if score > bestScore {
+				best = Action{UnitID: u.ID, MoveTo: pos, TargetID: e.ID}
+				bestScore = score
+				found = true
+			}
+		}
+	}
+	return best, found
+}
+
+// Score rates an attack, a likely kill beats everything else then it's
+// damage dealt minus half the damage expected back
+func Score(attacker, defender combat.Combatant) float64 {
+	dealt := ExpectedDamage(attacker, defender)
+	taken := 0.0
+	if combat.CanAttack(defender, attacker) {
+		taken = ExpectedDamage(defender, attacker)
+	}
+	return KillChance(attacker, defender)*killWeight + dealt - taken/2
+}
+
+func strikes(attacker, defender combat.Combatant) int {
+	if combat.Doubles(attacker, defender) {
+		return 2
+	}
+	return 1
+}
+
+func ExpectedDamage(attacker, defender combat.Combatant) float64 {
+	hit := float64(combat.HitRate(attacker, defender)) / 100
+	crit := float64(combat.CritRate(attacker, defender)) / 100
+	perStrike := hit * float64(combat.Damage(attacker, defender)) * (1 + crit*(combat.CritMultiplier-1))
+	return perStrike * float64(strikes(attacker, defender))
+}
+
+// Chance the attacker's strikes alone kill the defender, crits are ignored
+func KillChance(attacker, defender combat.Combatant) float64 {
+	damage := combat.Damage(attacker, defender)
+	if damage <= 0 {
+		return 0
+	}
+	needed := (defender.HP + damage - 1) / damage
+	n := strikes(attacker, defender)
+	if needed > n {
+		return 0
+	}
+
+	// At least `needed` hits out of n strikes
+	p := float64(combat.HitRate(attacker, defender)) / 100
+	chance := 0.0
+	for k := needed; k <= n; k++ {
+		chance += float64(binomial(n, k)) * math.Pow(p, float64(k)) * math.Pow(1-p, float64(n-k))
+	}
+	return chance
+}
+
+func binomial(n, k int) int {
+	r := 1
+	for i := 1; i <= k; i++ {
+		r = r * (n - k + i) / i
+	}
+	return r
+}
+
+// Moves to the tile closest (by the unit's own move costs, ignoring other units)
+// to any of the goals
+func moveToward(b *Board, u Unit, goals [][2]int) Action {
+	action := Action{UnitID: u.ID, MoveTo: u.Combatant.Pos, TargetID: NoTarget}
+	if len(goals) == 0 {
+		return action
+	}
+
+	unlimited := b.Width * b.Height * 100
+	fields := []pathfind.Tree{}
+	for _, goal := range goals {
+		fields = append(fields, pathfind.Reachable(goal, b.Width, b.Height, unlimited, b.terrain(u)))
+	}
+	distance := func(pos [2]int) int {
+		best := math.MaxInt
+		for _, f := range fields {
+			if d, ok := f.Cost[pos]; ok && d < best {
+				best = d
+			}
+		}
+		return best
+	}
+
+	bestDistance := distance(u.Combatant.Pos)
+	for _, pos := range b.Moves(u) {
+		if d := distance(pos); d < bestDistance {
+			action.MoveTo = pos
+			bestDistance = d
+		}
+	}
+	return action
+}
+
+// Moves to the tile furthest from the closest enemy
+func retreat(b *Board, u Unit) Action {
+	action := Action{UnitID: u.ID, MoveTo: u.Combatant.Pos, TargetID: NoTarget}
+	enemies := b.enemies(u)
+	if len(enemies) == 0 {
+		return action
+	}
+	closest := func(pos [2]int) int {
+		best := math.MaxInt
+		for _, e := range enemies {
+			best = min(best, combat.Distance(pos, e.Combatant.Pos))
+		}
+		return best
+	}
+
+	bestDistance := closest(u.Combatant.Pos)
+	for _, pos := range b.Moves(u) {
+		if d := closest(pos); d > bestDistance {
+			action.MoveTo = pos
+			bestDistance = d
+		}
+	}
+	return action
+}
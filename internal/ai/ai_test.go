@@ -0,0 +1,234 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"openFE/internal/combat"
+)
+
+const (
+	player = 0
+	enemy  = 1
+)
+
+var ironSword = combat.Weapon{Name: "Iron Sword", Might: 5, Hit: 90, MinRange: 1, MaxRange: 1}
+
+// '.' costs 1, '#' blocks
+func testBoard(rows []string, units ...Unit) *Board {
+	return &Board{
+		Width:  len(rows[0]),
+		Height: len(rows),
+		Units:  units,
+		MoveCost: func(id int, pos [2]int) int {
+			if rows[pos[1]][pos[0]] == '#' {
+				return -1
+			}
+			return 1
+		},
+	}
+}
+
+func testUnit(id, faction int, pos [2]int, behavior Behavior) Unit {
+	return Unit{
+		ID:       id,
+		Faction:  faction,
+		Move:     3,
+		MaxHP:    20,
+		Behavior: behavior,
+		Combatant: combat.Combatant{
+			ID: id, Pos: pos, HP: 20, Str: 6, Skl: 5, Spd: 5, Lck: 2, Def: 3, Weapon: ironSword,
+		},
+	}
+}
+
+func TestAggressivePrefersKill(t *testing.T) {
+	// Given
+	e := testUnit(0, enemy, [2]int{2, 2}, AGGRESSIVE)
+	healthy := testUnit(1, player, [2]int{2, 0}, AGGRESSIVE)
+	weak := testUnit(2, player, [2]int{4, 2}, AGGRESSIVE)
+	weak.Combatant.HP = 5
+	b := testBoard([]string{
+		".....",
+		".....",
+		".....",
+	}, e, healthy, weak)
+
+	// When
+	sut := Decide(b, e.ID)
+
+	// Then
+	assert.Equal(t, weak.ID, sut.TargetID)
+	assert.Equal(t, 1, combat.Distance(sut.MoveTo, weak.Combatant.Pos))
+}
+
+func TestAttacksFromCover(t *testing.T) {
+	// Given a forest next to the target, further away than the plains tile
+	e := testUnit(0, enemy, [2]int{0, 1}, AGGRESSIVE)
+	p := testUnit(1, player, [2]int{2, 1}, AGGRESSIVE)
+	rows := []string{
+		"..f",
+		"...",
+		"...",
+	}
+	b := testBoard(rows, e, p)
+
+	// When the board has no terrain bonuses
+	sut := Decide(b, e.ID)
+
+	// Then the closest tile wins
+	assert.Equal(t, [2]int{1, 1}, sut.MoveTo)
+
+	// When
+	b.Terrain = func(pos [2]int) combat.TerrainBonus {
+		if rows[pos[1]][pos[0]] == 'f' {
+			return combat.TerrainBonus{Def: 1, Avoid: 20}
+		}
+		return combat.TerrainBonus{}
+	}
+	sut = Decide(b, e.ID)
+
+	// Then it takes less damage from the counter in the forest
+	assert.Equal(t, Action{UnitID: e.ID, MoveTo: [2]int{2, 0}, TargetID: p.ID}, sut)
+}
+
+func TestAggressiveWalksTowardEnemyOutOfReach(t *testing.T) {
+	// Given
+	e := testUnit(0, enemy, [2]int{0, 0}, AGGRESSIVE)
+	p := testUnit(1, player, [2]int{7, 0}, AGGRESSIVE)
+	b := testBoard([]string{
+		"........",
+		"........",
+	}, e, p)
+
+	// When
+	sut := Decide(b, e.ID)
+
+	// Then
+	assert.Equal(t, Action{UnitID: e.ID, MoveTo: [2]int{3, 0}, TargetID: NoTarget}, sut)
+}
+
+func TestAggressivePathsAroundWalls(t *testing.T) {
+	// Given
+	e := testUnit(0, enemy, [2]int{0, 0}, AGGRESSIVE)
+	p := testUnit(1, player, [2]int{2, 0}, AGGRESSIVE)
+	b := testBoard([]string{
+		".#.",
+		".#.",
+		"...",
+	}, e, p)
+
+	// When
+	sut := Decide(b, e.ID)
+
+	// Then
+	assert.Equal(t, [2]int{1, 2}, sut.MoveTo)
+	assert.Equal(t, NoTarget, sut.TargetID)
+}
+
+func TestHoldNeverMoves(t *testing.T) {
+	// Given
+	e := testUnit(0, enemy, [2]int{0, 0}, HOLD)
+	far := testUnit(1, player, [2]int{2, 0}, AGGRESSIVE)
+	b := testBoard([]string{"...."}, e, far)
+
+	// When
+	sut := Decide(b, e.ID)
+
+	// Then
+	assert.Equal(t, Action{UnitID: e.ID, MoveTo: [2]int{0, 0}, TargetID: NoTarget}, sut)
+
+	// When the player steps next to it
+	b.Units[1].Combatant.Pos = [2]int{1, 0}
+	sut = Decide(b, e.ID)
+
+	// Then
+	assert.Equal(t, Action{UnitID: e.ID, MoveTo: [2]int{0, 0}, TargetID: far.ID}, sut)
+}
+
+func TestInRangeStaysWhenNothingReachable(t *testing.T) {
+	// Given
+	e := testUnit(0, enemy, [2]int{0, 0}, INRANGE)
+	p := testUnit(1, player, [2]int{7, 0}, AGGRESSIVE)
+	b := testBoard([]string{"........"}, e, p)
+
+	// When
+	sut := Decide(b, e.ID)
+
+	// Then
+	assert.Equal(t, Action{UnitID: e.ID, MoveTo: [2]int{0, 0}, TargetID: NoTarget}, sut)
+
+	// When
+	b.Units[1].Combatant.Pos = [2]int{4, 0}
+	sut = Decide(b, e.ID)
+
+	// Then
+	assert.Equal(t, Action{UnitID: e.ID, MoveTo: [2]int{3, 0}, TargetID: p.ID}, sut)
+}
+
+func TestGuardIgnoresFarEnemiesAndReturns(t *testing.T) {
+	// Given
+	e := testUnit(0, enemy, [2]int{5, 0}, GUARD)
+	e.GuardPos = [2]int{0, 0}
+	p := testUnit(1, player, [2]int{7, 0}, AGGRESSIVE)
+	b := testBoard([]string{"........"}, e, p)
+
+	// When
+	sut := Decide(b, e.ID)
+
+	// Then
+	assert.Equal(t, Action{UnitID: e.ID, MoveTo: [2]int{2, 0}, TargetID: NoTarget}, sut)
+}
+
+func TestRetreatWhenLowHP(t *testing.T) {
+	// Given
+	e := testUnit(0, enemy, [2]int{3, 0}, RETREAT)
+	e.Combatant.HP = 5
+	p := testUnit(1, player, [2]int{2, 0}, AGGRESSIVE)
+	b := testBoard([]string{"........"}, e, p)
+
+	// When
+	sut := Decide(b, e.ID)
+
+	// Then
+	assert.Equal(t, Action{UnitID: e.ID, MoveTo: [2]int{6, 0}, TargetID: NoTarget}, sut)
+
+	// When healthy it fights
+	b.Units[0].Combatant.HP = 20
+	sut = Decide(b, e.ID)
+
+	// Then
+	assert.Equal(t, p.ID, sut.TargetID)
+}
+
+func TestAlliesArentTargets(t *testing.T) {
+	// Given
+	e := testUnit(0, enemy, [2]int{0, 0}, AGGRESSIVE)
+	friend := testUnit(1, enemy, [2]int{1, 0}, AGGRESSIVE)
+	b := testBoard([]string{"..."}, e, friend)
+
+	// When
+	sut := Decide(b, e.ID)
+
+	// Then
+	assert.Equal(t, NoTarget, sut.TargetID)
+}
+
+func TestKillChance(t *testing.T) {
+	// Given
+	a := testUnit(0, enemy, [2]int{0, 0}, AGGRESSIVE).Combatant
+	d := testUnit(1, player, [2]int{1, 0}, AGGRESSIVE).Combatant
+	hit := float64(combat.HitRate(a, d)) / 100
+
+	// Then
+	d.HP = 8
+	assert.InDelta(t, hit, KillChance(a, d), 0.0001)
+	d.HP = 9
+	assert.Equal(t, 0.0, KillChance(a, d))
+
+	a.Spd = 10 // doubles
+	assert.InDelta(t, hit*hit, KillChance(a, d), 0.0001)
+	d.HP = 8
+	assert.InDelta(t, 1-(1-hit)*(1-hit), KillChance(a, d), 0.0001)
+}
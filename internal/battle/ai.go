@@ -0,0 +1,66 @@
+package battle
+
+import (
+	"openFE/internal/ai"
+	"openFE/internal/combat"
+)
+
+func (u *Unit) SetBehavior(behavior ai.Behavior, guardPos PosXY) {
+	u.behavior = behavior
+	u.guardPos = guardPos
+}
+
+// AIBoard is the headless view of the map the ai decides on
+func (b *Battle) AIBoard() *ai.Board {
+	units := []ai.Unit{}
+	for _, u := range b.Units {
+		if u.dead {
+			continue
+		}
+		units = append(units, ai.Unit{
+			ID:        u.id,
+			Faction:   int(u.faction),
+			Move:      u.rpg.Move(),
+			MaxHP:     u.rpg.Stats.HP,
+			Behavior:  u.behavior,
+			GuardPos:  u.guardPos,
+			Combatant: b.Combatant(u),
+		})
+	}
+
+	return &ai.Board{
+		Width:  b.Width(),
+		Height: b.Height(),
+		Units:  units,
+		MoveCost: func(id int, pos [2]int) int {
+			movementType := b.Units[id].rpg.Job.Data().MovementType
+			return b.grid[pos[Y]][pos[X]].terrain.MoveCost(movementType)
+		},
+		Terrain: func(pos [2]int) combat.TerrainBonus {
+			return b.grid[pos[Y]][pos[X]].terrain.Bonus()
+		},
+		Triangle: Triangle,
+		IsAlly: func(a, c int) bool {
+			return Faction(a).IsAlly(Faction(c))
+		},
+	}
+}
+
+// AICommands turns the ai's choice into the same commands a player would give
+func AICommands(action ai.Action) []Command {
+	commands := []Command{{Type: MOVE, UnitID: action.UnitID, To: action.MoveTo}}
+	if action.TargetID != ai.NoTarget {
+		return append(commands, Command{Type: ATTACK, UnitID: action.UnitID, TargetID: action.TargetID})
+	}
+	return append(commands, Command{Type: WAIT, UnitID: action.UnitID})
+}
+
+// NextAIUnit is the next unit the ai should move this phase, nil if everyone has acted
+func (b *Battle) NextAIUnit() *Unit {
+	for _, u := range b.Units {
+		if b.CanAct(u) {
+			return u
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package battle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"openFE/internal/ai"
+	"openFE/internal/combat"
+	"openFE/internal/rng"
+)
+
+func TestEnemyPhaseAIMovesAndAttacks(t *testing.T) {
+	// Given
+	p := testUnit(0, PLAYER, PosXY{4, 0}, 3)
+	e := testUnit(1, ENEMY, PosXY{0, 0}, 3)
+	e.rpg.Weapon = combat.Weapon{Name: "Iron Sword", Might: 5, Hit: 90, MinRange: 1, MaxRange: 1}
+	e.SetBehavior(ai.AGGRESSIVE, e.posXY)
+	b := testBattle([]string{"....."}, []*Unit{p, e})
+	b.EndPhase()
+
+	// When
+	action := ai.Decide(b.AIBoard(), e.id)
+	for _, cmd := range AICommands(action) {
+		_, err := b.Apply(cmd, rng.New(1))
+		assert.NoError(t, err)
+	}
+
+	// Then
+	assert.Equal(t, ai.Action{UnitID: e.id, MoveTo: [2]int{3, 0}, TargetID: p.id}, action)
+	assert.Equal(t, PosXY{3, 0}, e.posXY)
+	assert.Equal(t, e.id, b.UnitAt(PosXY{3, 0}))
+	assert.Equal(t, EmptyCell, b.UnitAt(PosXY{0, 0}))
+	assert.Equal(t, PLAYER, b.Phase()) // Only enemy acted so the phase ended
+	assert.Equal(t, 2, b.Turn())
+}
@@ -0,0 +1,114 @@
+// Package battle holds the rules of a fight: the map, the units, turns and
+// every command that changes them. It never touches ebiten so a battle can be
+// stepped with commands in plain go test, core draws whatever state it is in.
+package battle
+
+import "slices"
+
+type PosXY [2]int
+
+const (
+	X = 0
+	Y = 1
+)
+
+const EmptyCell = -1
+
+type Cell struct {
+	terrain Terrain
+	unitId  int
+}
+
+type Battle struct {
+	level   string // Identifier of the ldtk level the map comes from
+	turn    int
+	phase   Faction
+	grid    [][]Cell
+	Units   []*Unit // Indexed by unit id
+	changes int     // Goes up every time a unit moves, dies or a phase ends
+}
+
+// New starts a battle on turn 1 of the player phase. terrain is indexed [y][x].
+func New(level string, terrain [][]Terrain, units []*Unit) *Battle {
+	grid := make([][]Cell, len(terrain))
+	for y, row := range terrain {
+		grid[y] = make([]Cell, len(row))
+		for x, t := range row {
+			grid[y][x] = Cell{terrain: t, unitId: EmptyCell}
+		}
+	}
+	for _, u := range units {
+		if !u.dead {
+			grid[u.posXY[Y]][u.posXY[X]].unitId = u.id
+		}
+	}
+
+	return &Battle{
+		level: level,
+		turn:  1,
+		phase: PLAYER,
+		grid:  grid,
+		Units: units,
+	}
+}
+
+func (b *Battle) Level() string {
+	return b.level
+}
+
+// Changes is a counter that goes up whenever the board changes, caches compare it
+func (b *Battle) Changes() int {
+	return b.changes
+}
+
+// Map size in cells
+func (b *Battle) Width() int {
+	if len(b.grid) == 0 {
+		return 0
+	}
+	return len(b.grid[0])
+}
+
+func (b *Battle) Height() int {
+	return len(b.grid)
+}
+
+func (b *Battle) InBounds(posXY PosXY) bool {
+	return posXY[X] >= 0 && posXY[Y] >= 0 && posXY[X] < b.Width() && posXY[Y] < b.Height()
+}
+
+func (b *Battle) TerrainAt(posXY PosXY) Terrain {
+	return b.grid[posXY[Y]][posXY[X]].terrain
+}
+
+// Id of the unit standing on posXY, EmptyCell if there is none
+func (b *Battle) UnitAt(posXY PosXY) int {
+	return b.grid[posXY[Y]][posXY[X]].unitId
+}
+
+func (b *Battle) SetUnitPos(u *Unit, posXY PosXY) {
+	if b.UnitAt(u.posXY) == u.id {
+		b.grid[u.posXY[Y]][u.posXY[X]].unitId = EmptyCell
+	}
+	b.grid[posXY[Y]][posXY[X]].unitId = u.id
+	u.posXY = posXY
+	b.changes += 1
+}
+
+// Clone deep copies the map and the units so the copy can be played on
+// without touching the original
+func (b *Battle) Clone() *Battle {
+	c := *b
+	c.grid = make([][]Cell, len(b.grid))
+	for i, row := range b.grid {
+		c.grid[i] = slices.Clone(row)
+	}
+	c.Units = make([]*Unit, len(b.Units))
+	for i, u := range b.Units {
+		clone := *u
+		clone.posXYHistory = slices.Clone(u.posXYHistory)
+		clone.items = slices.Clone(u.items)
+		c.Units[i] = &clone
+	}
+	return &c
+}
@@ -0,0 +1,95 @@
+package battle
+
+import (
+	"openFE/internal/combat"
+)
+
+func (u *Unit) Combatant() combat.Combatant {
+	return combat.Combatant{
+		ID:     u.id,
+		Pos:    u.posXY,
+		HP:     u.rpg.HP,
+		Str:    u.rpg.Stats.Str,
+		Mag:    u.rpg.Stats.Mag,
+		Skl:    u.rpg.Stats.Skl,
+		Spd:    u.rpg.Stats.Spd,
+		Lck:    u.rpg.Stats.Lck,
+		Def:    u.rpg.Stats.Def,
+		Res:    u.rpg.Stats.Res,
+		Weapon: u.rpg.Weapon,
+	}
+}
+
+// Combatant of u with the bonus of the tile it stands on
+func (b *Battle) Combatant(u *Unit) combat.Combatant {
+	c := u.Combatant()
+	c.Terrain = b.TerrainAt(u.posXY).Bonus()
+	return c
+}
+
+// Min and max range over the unit's weapons, the equipped one and every one it
+// carries and can wield since it can switch before attacking. 0, 0 when it's unarmed.
+func (u *Unit) WeaponRange() (int, int) {
+	minRange, maxRange := u.rpg.Weapon.MinRange, u.rpg.Weapon.MaxRange
+	for _, item := range u.items {
+		if !u.CanWield(item) {
+			continue
+		}
+		w := item.Data().Weapon
+		if maxRange == 0 || w.MinRange < minRange {
+			minRange = w.MinRange
+		}
+		maxRange = max(maxRange, w.MaxRange)
+	}
+	return minRange, maxRange
+}
+
+func (u *Unit) IsDead() bool {
+	return u.dead
+}
+
+// Units that u can attack from where it is standing
+func (b *Battle) AttackTargets(u *Unit) []*Unit {
+	candidates := []combat.Combatant{}
+	for _, other := range b.Units {
+		if other.dead || other.faction.IsAlly(u.faction) {
+			continue
+		}
+		candidates = append(candidates, b.Combatant(other))
+	}
+
+	targets := []*Unit{}
+	for _, c := range combat.Targets(b.Combatant(u), candidates) {
+		targets = append(targets, b.Units[c.ID])
+	}
+	return targets
+}
+
+func (b *Battle) Attack(attacker, defender *Unit, roller combat.Roller) combat.Result {
+	a, d := b.Facing(attacker, defender)
+	result := combat.Resolve(a, d, roller)
+	b.ApplyCombat(result)
+	return result
+}
+
+// Writes the HP from a resolved exchange back to the units, wears down their
+// weapons and removes whoever died from the grid
+func (b *Battle) ApplyCombat(result combat.Result) {
+	b.wearWeapons(result)
+	for _, c := range []combat.Combatant{result.Attacker, result.Defender} {
+		u := b.Units[c.ID]
+		u.rpg.HP = c.HP
+		if c.HP <= 0 {
+			b.KillUnit(u)
+		}
+	}
+}
+
+func (b *Battle) KillUnit(u *Unit) {
+	u.rpg.HP = 0
+	u.dead = true
+	b.changes += 1
+	if b.UnitAt(u.posXY) == u.id {
+		b.grid[u.posXY[Y]][u.posXY[X]].unitId = EmptyCell
+	}
+}
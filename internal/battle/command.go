@@ -0,0 +1,267 @@
+package battle
+
+import (
+	"fmt"
+	"slices"
+
+	"openFE/internal/combat"
+)
+
+// CommandType is what a player or the ai asks a unit to do
+type CommandType string
+
+const (
+	SELECT   CommandType = "select"   // Pick a unit to move
+	MOVE     CommandType = "move"     // Move a unit to To, once per action
+	UNMOVE   CommandType = "unmove"   // Take back the move of a unit that hasn't finished its action
+	ATTACK   CommandType = "attack"   // Attack TargetID from where the unit stands, ends its action
+	USEITEM  CommandType = "useItem"  // Use the consumable in slot Item, ends the unit's action
+	EQUIP    CommandType = "equip"    // Equip the weapon in slot Item, the unit can still act
+	DISCARD  CommandType = "discard"  // Throw away the item in slot Item, the unit can still act
+	TRADE    CommandType = "trade"    // Swap slot Item with slot TargetItem of the adjacent ally TargetID, the unit can still act
+	WAIT     CommandType = "wait"     // End the unit's action
+	ENDPHASE CommandType = "endPhase" // End the phase for every unit that hasn't acted
+)
+
+// Command is a single game action. Every change to the battle goes through
+// Apply so commands can be recorded, replayed or sent over the network.
+type Command struct {
+	Type       CommandType `json:"type"`
+	UnitID     int         `json:"unit"`
+	To         PosXY       `json:"to"`
+	TargetID   int         `json:"target"`
+	Item       int         `json:"item"`
+	TargetItem int         `json:"targetItem"` // For TRADE, a slot past the end of an inventory is its free space
+}
+
+type EventType string
+
+const (
+	SELECTED   EventType = "selected"
+	MOVED      EventType = "moved"
+	UNMOVED    EventType = "unmoved" // The unit went back To where it stood before moving
+	STRUCK     EventType = "struck"  // One strike of an exchange, hit or miss
+	DIED       EventType = "died"
+	WAITED     EventType = "waited"
+	PHASEENDED EventType = "phaseEnded"
+	HEALED     EventType = "healed" // Terrain restored HP at the start of the unit's phase
+	USED       EventType = "used"   // A consumable restored Amount HP
+	EQUIPPED   EventType = "equipped"
+	DISCARDED  EventType = "discarded"
+	TRADED     EventType = "traded"
+	CLEARED    EventType = "cleared" // The last enemy of the player died, the level is won
+)
+
+// Event is something that happened while applying a command, what the UI
+// animates and what gets printed
+type Event struct {
+	Type   EventType
+	UnitID int
+	From   PosXY
+	To     PosXY
+	Strike combat.Strike
+	Phase  Faction // Phase that started, for PHASEENDED
+	Turn   int
+	Amount int    // HP restored, for HEALED and USED
+	Item   ItemID // For USED, EQUIPPED and DISCARDED
+	Target int    // Unit traded with, for TRADED
+}
+
+func (e Event) String() string {
+	switch e.Type {
+	case MOVED:
+		return fmt.Sprintf("unit %d moved %v -> %v", e.UnitID, e.From, e.To)
+	case UNMOVED:
+		return fmt.Sprintf("unit %d went back %v -> %v", e.UnitID, e.From, e.To)
+	case STRUCK:
+		s := e.Strike
+		return fmt.Sprintf("unit %d -> unit %d hit: %t crit: %t dmg: %d hp left: %d", s.AttackerID, s.DefenderID, s.Hit, s.Crit, s.Damage, s.DefenderHP)
+	case PHASEENDED:
+		return fmt.Sprintf("Turn %d: %s phase", e.Turn, e.Phase)
+	case HEALED:
+		return fmt.Sprintf("unit %d healed %d hp", e.UnitID, e.Amount)
+	case USED:
+		return fmt.Sprintf("unit %d used %s and healed %d hp", e.UnitID, e.Item, e.Amount)
+	case EQUIPPED, DISCARDED:
+		return fmt.Sprintf("unit %d %s %s", e.UnitID, e.Type, e.Item)
+	case TRADED:
+		return fmt.Sprintf("unit %d traded with unit %d", e.UnitID, e.Target)
+	case CLEARED:
+		return "Level cleared"
+	}
+	return fmt.Sprintf("unit %d %s", e.UnitID, e.Type)
+}
+
+// Apply checks cmd against the rules and plays it. Nothing changes when the
+// command is refused.
+func (b *Battle) Apply(cmd Command, roller combat.Roller) ([]Event, error) {
+	if cmd.Type == ENDPHASE {
+		return b.endPhase(), nil
+	}
+
+	if cmd.UnitID < 0 || cmd.UnitID >= len(b.Units) {
+		return nil, &CommandError{cmd, "unknown unit"}
+	}
+	u := b.Units[cmd.UnitID]
+	if !b.CanAct(u) {
+		return nil, &CommandError{cmd, "unit can't act this phase"}
+	}
+	if other := b.MidAction(); other != nil && other != u {
+		return nil, &CommandError{cmd, fmt.Sprintf("unit %d has to finish its action first", other.id)}
+	}
+
+	switch cmd.Type {
+	case SELECT:
+		return []Event{{Type: SELECTED, UnitID: u.id}}, nil
+
+	case MOVE:
+		if u.moved {
+			return nil, &CommandError{cmd, "unit already moved"}
+		}
+		if !slices.Contains(b.Reachable(u), cmd.To) {
+			return nil, &CommandError{cmd, "destination out of reach"}
+		}
+		from := u.posXY
+		b.SetUnitPos(u, cmd.To)
+		u.posXYAppendHistory(cmd.To)
+		u.moved = true
+		return []Event{{Type: MOVED, UnitID: u.id, From: from, To: cmd.To}}, nil
+
+	case UNMOVE:
+		if !u.moved {
+			return nil, &CommandError{cmd, "unit hasn't moved"}
+		}
+		from := u.posXY
+		to := u.posXYPopHistory()
+		b.SetUnitPos(u, to)
+		u.moved = false
+		return []Event{{Type: UNMOVED, UnitID: u.id, From: from, To: to}}, nil
+
+	case ATTACK:
+		if cmd.TargetID < 0 || cmd.TargetID >= len(b.Units) {
+			return nil, &CommandError{cmd, "unknown target"}
+		}
+		if !slices.Contains(b.AttackTargets(u), b.Units[cmd.TargetID]) {
+			return nil, &CommandError{cmd, "target out of range"}
+		}
+		result := b.Attack(u, b.Units[cmd.TargetID], roller)
+		events := []Event{}
+		for _, s := range result.Strikes {
+			events = append(events, Event{Type: STRUCK, UnitID: s.AttackerID, Strike: s})
+		}
+		for _, c := range []combat.Combatant{result.Attacker, result.Defender} {
+			if c.HP <= 0 {
+				events = append(events, Event{Type: DIED, UnitID: c.ID})
+			}
+		}
+		if b.Cleared() {
+			events = append(events, Event{Type: CLEARED})
+		}
+		return append(events, b.wait(u)...), nil
+
+	case WAIT:
+		return b.wait(u), nil
+
+	case USEITEM, EQUIP, DISCARD:
+		if cmd.Item < 0 || cmd.Item >= len(u.items) {
+			return nil, &CommandError{cmd, "no item in that slot"}
+		}
+		return b.applyItem(cmd, u)
+
+	case TRADE:
+		return b.trade(cmd, u)
+	}
+	return nil, &CommandError{cmd, "unknown command"}
+}
+
+func (b *Battle) applyItem(cmd Command, u *Unit) ([]Event, error) {
+	item := u.items[cmd.Item]
+	switch cmd.Type {
+	case USEITEM:
+		if !item.IsUsable() {
+			return nil, &CommandError{cmd, "item can't be used"}
+		}
+		if u.rpg.HP >= u.rpg.Stats.HP {
+			return nil, &CommandError{cmd, "unit is already at full HP"}
+		}
+		heal := u.useItem(cmd.Item)
+		b.changes += 1
+		events := []Event{{Type: USED, UnitID: u.id, Item: item.ID, Amount: heal}}
+		return append(events, b.wait(u)...), nil
+
+	case EQUIP:
+		if !item.IsWeapon() {
+			return nil, &CommandError{cmd, "item isn't a weapon"}
+		}
+		if !u.CanWield(item) {
+			return nil, &CommandError{cmd, "unit's job can't wield that weapon"}
+		}
+		u.equip(cmd.Item)
+		b.changes += 1
+		return []Event{{Type: EQUIPPED, UnitID: u.id, Item: item.ID}}, nil
+	}
+
+	u.discard(cmd.Item)
+	b.changes += 1
+	return []Event{{Type: DISCARDED, UnitID: u.id, Item: item.ID}}, nil
+}
+
+// TradePartners are the living allies next to u
+func (b *Battle) TradePartners(u *Unit) []*Unit {
+	partners := []*Unit{}
+	for _, other := range b.Units {
+		if other != u && !other.dead && other.faction.IsAlly(u.faction) && combat.Distance(u.posXY, other.posXY) == 1 {
+			partners = append(partners, other)
+		}
+	}
+	return partners
+}
+
+func (b *Battle) trade(cmd Command, u *Unit) ([]Event, error) {
+	if cmd.TargetID < 0 || cmd.TargetID >= len(b.Units) || cmd.TargetID == u.id {
+		return nil, &CommandError{cmd, "unknown target"}
+	}
+	other := b.Units[cmd.TargetID]
+	if !slices.Contains(b.TradePartners(u), other) {
+		return nil, &CommandError{cmd, "can only trade with an adjacent ally"}
+	}
+	if cmd.Item < 0 || cmd.Item > len(u.items) || cmd.TargetItem < 0 || cmd.TargetItem > len(other.items) {
+		return nil, &CommandError{cmd, "no item in that slot"}
+	}
+	mine, theirs := cmd.Item < len(u.items), cmd.TargetItem < len(other.items)
+	switch {
+	case !mine && !theirs:
+		return nil, &CommandError{cmd, "no item to trade"}
+	case !mine && len(u.items) >= MaxItems, !theirs && len(other.items) >= MaxItems:
+		return nil, &CommandError{cmd, "inventory is full"}
+	}
+	u.trade(cmd.Item, other, cmd.TargetItem)
+	b.changes += 1
+	return []Event{{Type: TRADED, UnitID: u.id, Target: other.id}}, nil
+}
+
+// MidAction is the unit that moved but hasn't attacked or waited yet, nil if there is none
+func (b *Battle) MidAction() *Unit {
+	for _, u := range b.Units {
+		if u.moved && !u.waited && !u.dead {
+			return u
+		}
+	}
+	return nil
+}
+
+// Ends u's action and the phase with it if u was the last one
+func (b *Battle) wait(u *Unit) []Event {
+	phase, turn := b.phase, b.turn
+	events := []Event{{Type: WAITED, UnitID: u.id}}
+	heals := b.Wait(u)
+	if b.phase != phase || b.turn != turn {
+		events = append(events, Event{Type: PHASEENDED, Phase: b.phase, Turn: b.turn})
+	}
+	return append(events, heals...)
+}
+
+func (b *Battle) endPhase() []Event {
+	heals := b.EndPhase()
+	return append([]Event{{Type: PHASEENDED, Phase: b.phase, Turn: b.turn}}, heals...)
+}
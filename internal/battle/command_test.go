@@ -0,0 +1,133 @@
+package battle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"openFE/internal/combat"
+	"openFE/internal/rng"
+)
+
+var sureHit = combat.Weapon{Name: "Sure Hit", Might: 30, Hit: 200, MinRange: 1, MaxRange: 1}
+
+func TestMoveThenWait(t *testing.T) {
+	// Given
+	p := testUnit(0, PLAYER, PosXY{0, 0}, 2)
+	other := testUnit(1, PLAYER, PosXY{0, 1}, 2)
+	b := testBattle([]string{"....", "...."}, []*Unit{p, other})
+
+	// When
+	events, err := b.Apply(Command{Type: MOVE, UnitID: p.id, To: PosXY{2, 0}}, rng.New(1))
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []Event{{Type: MOVED, UnitID: p.id, From: PosXY{0, 0}, To: PosXY{2, 0}}}, events)
+	assert.Equal(t, p.id, b.UnitAt(PosXY{2, 0}))
+
+	// When it tries to move again or someone else acts
+	_, moveErr := b.Apply(Command{Type: MOVE, UnitID: p.id, To: PosXY{3, 0}}, rng.New(1))
+	_, otherErr := b.Apply(Command{Type: WAIT, UnitID: other.id}, rng.New(1))
+
+	// Then
+	assert.Error(t, moveErr)
+	assert.Error(t, otherErr)
+
+	// When
+	events, err = b.Apply(Command{Type: WAIT, UnitID: p.id}, rng.New(1))
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []Event{{Type: WAITED, UnitID: p.id}}, events)
+	assert.False(t, b.CanAct(p))
+	assert.True(t, b.CanAct(other))
+}
+
+func TestRefusedCommandChangesNothing(t *testing.T) {
+	// Given
+	p := testUnit(0, PLAYER, PosXY{0, 0}, 2)
+	e := testUnit(1, ENEMY, PosXY{3, 0}, 2)
+	b := testBattle([]string{"....."}, []*Unit{p, e})
+	before := b.Clone()
+
+	// When
+	_, outOfReach := b.Apply(Command{Type: MOVE, UnitID: p.id, To: PosXY{4, 0}}, rng.New(1))
+	_, outOfRange := b.Apply(Command{Type: ATTACK, UnitID: p.id, TargetID: e.id}, rng.New(1))
+	_, notTheirPhase := b.Apply(Command{Type: WAIT, UnitID: e.id}, rng.New(1))
+	_, noItems := b.Apply(Command{Type: USEITEM, UnitID: p.id}, rng.New(1))
+	_, noTrade := b.Apply(Command{Type: TRADE, UnitID: p.id, TargetID: e.id}, rng.New(1))
+
+	// Then
+	for _, err := range []error{outOfReach, outOfRange, notTheirPhase, noItems, noTrade} {
+		assert.IsType(t, &CommandError{}, err)
+	}
+	assert.Equal(t, before.grid, b.grid)
+	assert.Equal(t, *before.Units[p.id], *b.Units[p.id])
+}
+
+func TestAttackKillEndsPhase(t *testing.T) {
+	// Given
+	p := testUnit(0, PLAYER, PosXY{0, 0}, 2)
+	p.rpg.Weapon = sureHit
+	e := testUnit(1, ENEMY, PosXY{3, 0}, 2)
+	b := testBattle([]string{"....."}, []*Unit{p, e})
+	b.Apply(Command{Type: MOVE, UnitID: p.id, To: PosXY{2, 0}}, rng.New(1))
+
+	// When
+	events, err := b.Apply(Command{Type: ATTACK, UnitID: p.id, TargetID: e.id}, rng.New(1))
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []EventType{STRUCK, DIED, CLEARED, WAITED, PHASEENDED}, eventTypes(events))
+	assert.True(t, e.IsDead())
+	assert.Equal(t, Event{Type: PHASEENDED, Phase: PLAYER, Turn: 2}, events[4]) // No enemies left to take a phase
+}
+
+func TestEndPhaseCommand(t *testing.T) {
+	// Given
+	p := testUnit(0, PLAYER, PosXY{0, 0}, 2)
+	e := testUnit(1, ENEMY, PosXY{3, 0}, 2)
+	b := testBattle([]string{"....."}, []*Unit{p, e})
+
+	// When
+	events, err := b.Apply(Command{Type: ENDPHASE}, rng.New(1))
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []Event{{Type: PHASEENDED, Phase: ENEMY, Turn: 1}}, events)
+}
+
+func eventTypes(events []Event) []EventType {
+	types := []EventType{}
+	for _, e := range events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+func TestUnmoveReturnsToOrigin(t *testing.T) {
+	// Given
+	p := testUnit(0, PLAYER, PosXY{0, 0}, 2)
+	other := testUnit(1, PLAYER, PosXY{0, 1}, 2)
+	b := testBattle([]string{"....", "...."}, []*Unit{p, other})
+	_, notMoved := b.Apply(Command{Type: UNMOVE, UnitID: p.id}, rng.New(1))
+	b.Apply(Command{Type: MOVE, UnitID: p.id, To: PosXY{2, 0}}, rng.New(1))
+
+	// When
+	events, err := b.Apply(Command{Type: UNMOVE, UnitID: p.id}, rng.New(1))
+
+	// Then the unit can act again from where it started
+	assert.IsType(t, &CommandError{}, notMoved)
+	assert.NoError(t, err)
+	assert.Equal(t, []Event{{Type: UNMOVED, UnitID: p.id, From: PosXY{2, 0}, To: PosXY{0, 0}}}, events)
+	assert.Equal(t, p.id, b.UnitAt(PosXY{0, 0}))
+	assert.Equal(t, EmptyCell, b.UnitAt(PosXY{2, 0}))
+	assert.Nil(t, b.MidAction())
+	assert.Equal(t, []PosXY{{0, 0}}, p.posXYHistory)
+
+	// When
+	_, err = b.Apply(Command{Type: MOVE, UnitID: p.id, To: PosXY{1, 0}}, rng.New(1))
+
+	// Then
+	assert.NoError(t, err)
+}
@@ -0,0 +1,24 @@
+package battle
+
+import "fmt"
+
+// FieldError points at the data file and field that failed to load so designers know what to fix
+type FieldError struct {
+	File  string
+	Field string
+	Msg   string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: field %q: %s", e.File, e.Field, e.Msg)
+}
+
+// CommandError is returned when a command breaks the rules, the battle is left untouched
+type CommandError struct {
+	Cmd Command
+	Msg string
+}
+
+func (e *CommandError) Error() string {
+	return fmt.Sprintf("%s unit %d: %s", e.Cmd.Type, e.Cmd.UnitID, e.Msg)
+}
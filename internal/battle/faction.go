@@ -0,0 +1,31 @@
+package battle
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Faction int
+
+const (
+	PLAYER Faction = iota
+	ENEMY
+	OTHER // Green units, they fight on the player's side
+)
+
+func (f Faction) IsAlly(other Faction) bool {
+	if f == other {
+		return true
+	}
+	return (f == PLAYER && other == OTHER) || (f == OTHER && other == PLAYER)
+}
+
+// ParseFaction reads a faction by its name (Ex: "Enemy"), case doesn't matter
+func ParseFaction(name string) (Faction, error) {
+	for _, f := range phaseOrder {
+		if strings.EqualFold(f.String(), name) {
+			return f, nil
+		}
+	}
+	return PLAYER, fmt.Errorf("unknown faction %q", name)
+}
@@ -0,0 +1,112 @@
+package battle
+
+import (
+	"slices"
+
+	"openFE/internal/combat"
+)
+
+// Equipped is the slot of the weapon the unit fights with, the first one it can
+// wield. -1 if it carries none.
+func (u *Unit) Equipped() int {
+	return slices.IndexFunc(u.items, u.CanWield)
+}
+
+// CanWield is true when item is a weapon of a type u's job has a rank in
+func (u *Unit) CanWield(item Item) bool {
+	return item.IsWeapon() && u.rpg.Job.Data().CanWield(item.Data().Weapon.Type)
+}
+
+// Points the unit's combat weapon at the equipped item. A unit that lost its
+// last weapon fights unarmed, one that never carried items keeps its weapon.
+func (u *Unit) equipWeapon() {
+	if i := u.Equipped(); i != -1 {
+		u.rpg.Weapon = *u.items[i].Data().Weapon
+	}
+}
+
+// GiveItem adds a new copy of item id to the inventory, false if it's full or
+// the item doesn't exist
+func (u *Unit) GiveItem(id ItemID) bool {
+	if _, ok := Items[id]; !ok || len(u.items) >= MaxItems {
+		return false
+	}
+	u.items = append(u.items, NewItem(id))
+	u.equipWeapon()
+	return true
+}
+
+// Moves the weapon in slot to the top of the inventory, which equips it
+func (u *Unit) equip(slot int) {
+	item := u.items[slot]
+	u.items = slices.Insert(slices.Delete(u.items, slot, slot+1), 0, item)
+	u.equipWeapon()
+}
+
+func (u *Unit) discard(slot int) {
+	weapon := u.items[slot].IsWeapon()
+	u.items = slices.Delete(u.items, slot, slot+1)
+	if weapon {
+		u.reequip()
+	}
+}
+
+// Equips the first weapon left after the inventory changed, unarmed if there is none
+func (u *Unit) reequip() {
+	u.rpg.Weapon = combat.Weapon{}
+	u.equipWeapon()
+}
+
+// Swaps u's slot with other's slot. A slot past the end of an inventory is its
+// free space so the item on the other side is handed over.
+func (u *Unit) trade(slot int, other *Unit, otherSlot int) {
+	switch {
+	case slot < len(u.items) && otherSlot < len(other.items):
+		u.items[slot], other.items[otherSlot] = other.items[otherSlot], u.items[slot]
+	case slot < len(u.items):
+		other.items = append(other.items, u.items[slot])
+		u.items = slices.Delete(u.items, slot, slot+1)
+	default:
+		u.items = append(u.items, other.items[otherSlot])
+		other.items = slices.Delete(other.items, otherSlot, otherSlot+1)
+	}
+	u.reequip()
+	other.reequip()
+}
+
+// Takes a use off the item in slot, it breaks when none are left
+func (u *Unit) useUp(slot int) (broke bool) {
+	u.items[slot].Uses -= 1
+	if u.items[slot].Uses > 0 {
+		return false
+	}
+	u.discard(slot)
+	return true
+}
+
+// Heals u with the consumable in slot and returns how much it healed
+func (u *Unit) useItem(slot int) int {
+	heal := min(u.items[slot].Data().Heal, u.rpg.Stats.HP-u.rpg.HP)
+	u.rpg.HP += heal
+	u.useUp(slot)
+	return heal
+}
+
+// Every strike a unit made wears the weapon it struck with down by one use. A
+// weapon that breaks mid exchange still finishes it, the one equipped after it
+// didn't strike.
+func (b *Battle) wearWeapons(result combat.Result) {
+	slots := map[int]int{} // Unit id -> slot of the weapon it strikes with, -1 once it broke
+	for _, s := range result.Strikes {
+		u := b.Units[s.AttackerID]
+		slot, ok := slots[u.id]
+		if !ok {
+			slot = u.Equipped()
+		}
+		if slot != -1 && u.useUp(slot) {
+			b.changes += 1 // Its attack range may have changed
+			slot = -1
+		}
+		slots[u.id] = slot
+	}
+}
@@ -0,0 +1,262 @@
+package battle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"openFE/internal/combat"
+	"openFE/internal/rng"
+)
+
+// Player at (0, 0) carrying items, with an enemy at (2, 0)
+func testInventory(items ...Item) (*Battle, *Unit, *Unit) {
+	p := testUnit(0, PLAYER, PosXY{0, 0}, 2)
+	p.items = items
+	p.equipWeapon()
+	e := testUnit(1, ENEMY, PosXY{2, 0}, 2)
+	return testBattle([]string{"....."}, []*Unit{p, e}), p, e
+}
+
+func TestGiveItemUntilFull(t *testing.T) {
+	// Given an unarmed unit
+	_, p, _ := testInventory()
+	p.rpg.Weapon = combat.Weapon{}
+
+	// When
+	given := p.GiveItem("javelin")
+
+	// Then it's armed
+	assert.True(t, given)
+	assert.Equal(t, "Javelin", p.rpg.Weapon.Name)
+
+	// When the inventory fills up
+	for i := 1; i < MaxItems; i++ {
+		assert.True(t, p.GiveItem("potion"))
+	}
+
+	// Then
+	assert.False(t, p.GiveItem("iron_lance"))
+	assert.Len(t, p.Items(), MaxItems)
+}
+
+func TestUnknownItem(t *testing.T) {
+	// Given
+	_, p, _ := testInventory()
+
+	// When
+	given := p.GiveItem("typo")
+
+	// Then it's refused and a stray copy is harmless
+	assert.False(t, given)
+	assert.Empty(t, p.Items())
+	stray := Item{ID: "typo"}
+	assert.Equal(t, "typo", stray.Data().Name)
+	assert.False(t, stray.IsWeapon())
+	assert.False(t, stray.IsUsable())
+	assert.Equal(t, 0, NewItem("typo").Uses)
+}
+
+func TestEquipMovesWeaponToTop(t *testing.T) {
+	// Given
+	b, p, _ := testInventory(Item{"iron_lance", 45}, Item{"potion", 3}, Item{"javelin", 20})
+	assert.Equal(t, "Iron Lance", p.rpg.Weapon.Name)
+
+	// When
+	events, err := b.Apply(Command{Type: EQUIP, UnitID: p.id, Item: 2}, rng.New(1))
+
+	// Then the unit can still act with its new weapon
+	assert.NoError(t, err)
+	assert.Equal(t, []Event{{Type: EQUIPPED, UnitID: p.id, Item: "javelin"}}, events)
+	assert.Equal(t, []Item{{"javelin", 20}, {"iron_lance", 45}, {"potion", 3}}, p.Items())
+	assert.Equal(t, "Javelin", p.rpg.Weapon.Name)
+	assert.True(t, b.CanAct(p))
+}
+
+func TestUseItemHealsAndEndsAction(t *testing.T) {
+	// Given
+	b, p, _ := testInventory(Item{"potion", 1})
+	p.rpg.HP = 15
+
+	// When
+	events, err := b.Apply(Command{Type: USEITEM, UnitID: p.id, Item: 0}, rng.New(1))
+
+	// Then the last use heals up to max HP and the potion is gone
+	assert.NoError(t, err)
+	assert.Equal(t, Event{Type: USED, UnitID: p.id, Item: "potion", Amount: 5}, events[0])
+	assert.Equal(t, WAITED, events[1].Type)
+	assert.Equal(t, 20, p.rpg.HP)
+	assert.Empty(t, p.Items())
+}
+
+func TestDiscardLastWeaponLeavesUnitUnarmed(t *testing.T) {
+	// Given
+	b, p, _ := testInventory(Item{"iron_lance", 45}, Item{"potion", 3})
+
+	// When
+	events, err := b.Apply(Command{Type: DISCARD, UnitID: p.id, Item: 0}, rng.New(1))
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []Event{{Type: DISCARDED, UnitID: p.id, Item: "iron_lance"}}, events)
+	assert.Equal(t, []Item{{"potion", 3}}, p.Items())
+	assert.Equal(t, -1, p.Equipped())
+	b.SetUnitPos(p, PosXY{1, 0})
+	assert.Empty(t, b.AttackTargets(p))
+}
+
+func TestRefusedItemCommands(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  Command
+		msg  string
+	}{
+		{"empty slot", Command{Type: EQUIP, Item: 2}, "no item in that slot"},
+		{"equip consumable", Command{Type: EQUIP, Item: 1}, "isn't a weapon"},
+		{"use weapon", Command{Type: USEITEM, Item: 0}, "can't be used"},
+		{"use at full hp", Command{Type: USEITEM, Item: 1}, "full HP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			b, p, _ := testInventory(Item{"iron_lance", 45}, Item{"potion", 3})
+
+			// When
+			_, err := b.Apply(tt.cmd, rng.New(1))
+
+			// Then
+			assert.ErrorContains(t, err, tt.msg)
+			assert.Equal(t, []Item{{"iron_lance", 45}, {"potion", 3}}, p.Items())
+		})
+	}
+}
+
+func TestJobLimitsWieldedWeapons(t *testing.T) {
+	// Given a lance unit carrying a sword first
+	b, p, _ := testInventory(Item{"iron_sword", 46}, Item{"iron_lance", 45})
+
+	// When
+	_, err := b.Apply(Command{Type: EQUIP, UnitID: p.id, Item: 0}, rng.New(1))
+
+	// Then the lance is the one it fights with
+	assert.ErrorContains(t, err, "can't wield")
+	assert.Equal(t, 1, p.Equipped())
+	assert.Equal(t, "Iron Lance", p.rpg.Weapon.Name)
+}
+
+func TestStrikesWearWeaponsDown(t *testing.T) {
+	// Given a lance with one use left and a defender that counters
+	b, p, e := testInventory(Item{"iron_lance", 1}, Item{"javelin", 20})
+	e.items = []Item{{"iron_lance", 45}}
+	e.equipWeapon()
+	b.SetUnitPos(p, PosXY{1, 0})
+	changes := b.Changes()
+
+	// When
+	result := b.Attack(p, e, rng.New(1))
+
+	// Then the lance broke and the javelin is equipped, the counter used one lance
+	assert.Equal(t, []Item{{"javelin", 20}}, p.Items())
+	assert.Equal(t, "Javelin", p.rpg.Weapon.Name)
+	counters := 0
+	for _, s := range result.Strikes {
+		if s.AttackerID == e.id {
+			counters++
+		}
+	}
+	assert.Equal(t, []Item{{"iron_lance", 45 - counters}}, e.Items())
+	assert.Greater(t, b.Changes(), changes)
+}
+
+func TestBrokenWeaponDoesNotWearTheNextOne(t *testing.T) {
+	// Given a lance with one use left that strikes twice
+	b, p, e := testInventory(Item{"iron_lance", 1}, Item{"javelin", 20})
+	strike := combat.Strike{AttackerID: p.id, DefenderID: e.id}
+
+	// When
+	b.ApplyCombat(combat.Result{
+		Strikes:  []combat.Strike{strike, strike},
+		Attacker: b.Combatant(p),
+		Defender: b.Combatant(e),
+	})
+
+	// Then only the lance wore down
+	assert.Equal(t, []Item{{"javelin", 20}}, p.Items())
+}
+
+func TestTrade(t *testing.T) {
+	tests := []struct {
+		name       string
+		mine       []Item
+		theirs     []Item
+		slot       int
+		targetSlot int
+		expected   []Item
+		other      []Item
+	}{
+		{"swap", []Item{{"iron_lance", 45}, {"potion", 3}}, []Item{{"javelin", 20}}, 0, 0, []Item{{"javelin", 20}, {"potion", 3}}, []Item{{"iron_lance", 45}}},
+		{"give", []Item{{"iron_lance", 45}, {"potion", 3}}, nil, 0, 0, []Item{{"potion", 3}}, []Item{{"iron_lance", 45}}},
+		{"take", []Item{{"potion", 3}}, []Item{{"javelin", 20}}, 1, 0, []Item{{"potion", 3}, {"javelin", 20}}, []Item{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given an ally next to the unit
+			b, p, _ := testInventory(tt.mine...)
+			ally := testUnit(2, PLAYER, PosXY{0, 0}, 2)
+			b.Units = append(b.Units, ally)
+			b.SetUnitPos(ally, PosXY{1, 0})
+			ally.items = tt.theirs
+			ally.equipWeapon()
+
+			// When
+			events, err := b.Apply(Command{Type: TRADE, UnitID: p.id, TargetID: ally.id, Item: tt.slot, TargetItem: tt.targetSlot}, rng.New(1))
+
+			// Then both units fight with their new first weapon and p can still act
+			assert.NoError(t, err)
+			assert.Equal(t, []Event{{Type: TRADED, UnitID: p.id, Target: ally.id}}, events)
+			assert.Equal(t, tt.expected, p.Items())
+			assert.Equal(t, tt.other, ally.Items())
+			for _, u := range []*Unit{p, ally} {
+				if i := u.Equipped(); i != -1 {
+					assert.Equal(t, *u.items[i].Data().Weapon, u.rpg.Weapon)
+				} else {
+					assert.Equal(t, combat.Weapon{}, u.rpg.Weapon)
+				}
+			}
+			assert.True(t, b.CanAct(p))
+		})
+	}
+}
+
+func TestTradeRefused(t *testing.T) {
+	// Given a full ally next to the unit and one out of reach
+	b, p, e := testInventory(Item{"iron_lance", 45})
+	full := testUnit(2, PLAYER, PosXY{0, 0}, 2)
+	far := testUnit(3, PLAYER, PosXY{0, 0}, 2)
+	b.Units = append(b.Units, full, far)
+	b.SetUnitPos(full, PosXY{1, 0})
+	b.SetUnitPos(far, PosXY{4, 0})
+	for i := 0; i < MaxItems; i++ {
+		full.GiveItem("potion")
+	}
+	before := b.Clone()
+
+	// When
+	_, giveToFull := b.Apply(Command{Type: TRADE, UnitID: p.id, TargetID: full.id, Item: 0, TargetItem: MaxItems}, rng.New(1))
+	_, nothing := b.Apply(Command{Type: TRADE, UnitID: p.id, TargetID: full.id, Item: 1, TargetItem: MaxItems}, rng.New(1))
+	_, tooFar := b.Apply(Command{Type: TRADE, UnitID: p.id, TargetID: far.id}, rng.New(1))
+	_, enemy := b.Apply(Command{Type: TRADE, UnitID: p.id, TargetID: e.id}, rng.New(1))
+	_, badSlot := b.Apply(Command{Type: TRADE, UnitID: p.id, TargetID: full.id, Item: 2}, rng.New(1))
+
+	// Then
+	assert.ErrorContains(t, giveToFull, "inventory is full")
+	assert.ErrorContains(t, nothing, "no item to trade")
+	assert.ErrorContains(t, tooFar, "adjacent ally")
+	assert.ErrorContains(t, enemy, "adjacent ally")
+	assert.ErrorContains(t, badSlot, "no item in that slot")
+	assert.Equal(t, []*Unit{full}, b.TradePartners(p))
+	assert.Equal(t, before.Units[p.id].Items(), p.Items())
+	assert.Equal(t, before.Units[full.id].Items(), full.Items())
+}
@@ -0,0 +1,150 @@
+package battle
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"openFE/internal/combat"
+)
+
+const (
+	ItemsDir = "assets/demo/items"
+	MaxItems = 5 // Inventory slots of a unit
+)
+
+// ItemID is the id of an item definition, the file name without .json (Ex: "iron_sword")
+type ItemID string
+
+type ItemData struct {
+	Name   string         `json:"name"`
+	Uses   int            `json:"uses"`
+	Icon   int            `json:"icon"`   // Index of the item's icon in the item icon sheet
+	Heal   int            `json:"heal"`   // HP restored when used, 0 for items that can't be used
+	Weapon *combat.Weapon `json:"weapon"` // nil for items that can't be attacked with
+}
+
+// Item is one copy of an item carried by a unit
+type Item struct {
+	ID   ItemID `json:"id"`
+	Uses int    `json:"uses"` // Uses left, the item breaks once it reaches 0
+}
+
+// NewItem is an unused copy of the item definition id
+func NewItem(id ItemID) Item {
+	return Item{ID: id, Uses: Item{ID: id}.Data().Uses}
+}
+
+// Data is the item's definition, an unknown item is named after its id and does nothing
+func (it Item) Data() *ItemData {
+	data, ok := Items[it.ID]
+	if !ok {
+		return &ItemData{Name: string(it.ID)}
+	}
+	return data
+}
+
+func (it Item) IsWeapon() bool {
+	return it.Data().Weapon != nil
+}
+
+// Usable items are consumed from the item menu instead of being attacked with
+func (it Item) IsUsable() bool {
+	return it.Data().Heal > 0
+}
+
+// Items holds every loaded item definition, filled by LoadItems
+var Items = map[ItemID]*ItemData{}
+
+// LoadItems reads every *.json file in dir as an item definition.
+// Nothing is registered unless every file is valid.
+func LoadItems(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no item definitions found in %s", dir)
+	}
+	sort.Strings(files)
+
+	items := map[ItemID]*ItemData{}
+	for _, file := range files {
+		data, err := loadItem(file)
+		if err != nil {
+			return err
+		}
+		if err := data.validate(file); err != nil {
+			return err
+		}
+		if data.Weapon != nil {
+			data.Weapon.Name = data.Name
+		}
+		items[ItemID(strings.TrimSuffix(filepath.Base(file), ".json"))] = data
+	}
+
+	Items = items
+	return nil
+}
+
+func loadItem(file string) (*ItemData, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	data := &ItemData{}
+	decoder := json.NewDecoder(f)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(data); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return data, nil
+}
+
+func (data *ItemData) validate(file string) error {
+	if data.Name == "" {
+		return &FieldError{file, "name", "is required"}
+	}
+	if data.Uses <= 0 {
+		return &FieldError{file, "uses", "must be greater than 0"}
+	}
+	if data.Icon < 0 {
+		return &FieldError{file, "icon", "must not be negative"}
+	}
+	if data.Heal < 0 {
+		return &FieldError{file, "heal", "must not be negative"}
+	}
+	if data.Weapon == nil && data.Heal == 0 {
+		return &FieldError{file, "heal", "is required for items that aren't weapons"}
+	}
+	if w := data.Weapon; w != nil {
+		if w.Name != "" {
+			return &FieldError{file, "weapon.name", "is taken from the item's name"}
+		}
+		if !slices.Contains(combat.WeaponTypes, w.Type) {
+			return &FieldError{file, "weapon.type", fmt.Sprintf("must be one of %v", combat.WeaponTypes)}
+		}
+		if w.Might < 0 {
+			return &FieldError{file, "weapon.might", "must not be negative"}
+		}
+		if w.Hit < 0 {
+			return &FieldError{file, "weapon.hit", "must not be negative"}
+		}
+		if w.Crit < 0 {
+			return &FieldError{file, "weapon.crit", "must not be negative"}
+		}
+		if w.MinRange < 1 {
+			return &FieldError{file, "weapon.minRange", "must be at least 1"}
+		}
+		if w.MaxRange < w.MinRange {
+			return &FieldError{file, "weapon.maxRange", "must be at least minRange"}
+		}
+	}
+	return nil
+}
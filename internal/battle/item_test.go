@@ -0,0 +1,98 @@
+package battle
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const validItem = `{
+	"name": "Iron Lance",
+	"uses": 45,
+	"weapon": { "type": "lance", "might": 7, "hit": 80, "crit": 0, "minRange": 1, "maxRange": 1 }
+}`
+
+func writeItemDir(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestLoadItems(t *testing.T) {
+	keepRegistries(t)
+	// Given
+	dir := writeItemDir(t, map[string]string{
+		"iron_lance.json": validItem,
+		"potion.json":     `{ "name": "Potion", "uses": 3, "icon": 3, "heal": 10 }`,
+	})
+
+	// When
+	err := LoadItems(dir)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "Iron Lance", Items["iron_lance"].Weapon.Name)
+	assert.Equal(t, 7, Items["iron_lance"].Weapon.Might)
+	assert.Nil(t, Items["potion"].Weapon)
+	assert.Equal(t, Item{ID: "potion", Uses: 3}, NewItem("potion"))
+	assert.True(t, NewItem("potion").IsUsable())
+	assert.False(t, NewItem("potion").IsWeapon())
+}
+
+func TestLoadItemsErrorsNameFileAndField(t *testing.T) {
+	keepRegistries(t)
+	tests := []struct {
+		name    string
+		content string
+		field   string
+	}{
+		{"missing name", strings.Replace(validItem, `"Iron Lance"`, `""`, 1), "name"},
+		{"no uses", strings.Replace(validItem, `45`, `0`, 1), "uses"},
+		{"negative icon", strings.Replace(validItem, `"uses": 45`, `"uses": 45, "icon": -1`, 1), "icon"},
+		{"negative heal", strings.Replace(validItem, `"uses": 45`, `"uses": 45, "heal": -5`, 1), "heal"},
+		{"does nothing", `{ "name": "Rock", "uses": 1 }`, "heal"},
+		{"no weapon type", strings.Replace(validItem, `"type": "lance", `, ``, 1), "weapon.type"},
+		{"unknown weapon type", strings.Replace(validItem, `"lance"`, `"spoon"`, 1), "weapon.type"},
+		{"negative might", strings.Replace(validItem, `"might": 7`, `"might": -1`, 1), "weapon.might"},
+		{"no range", strings.Replace(validItem, `"minRange": 1`, `"minRange": 0`, 1), "weapon.minRange"},
+		{"range backwards", strings.Replace(validItem, `"minRange": 1`, `"minRange": 2`, 1), "weapon.maxRange"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			dir := writeItemDir(t, map[string]string{"iron_lance.json": tt.content})
+
+			// When
+			err := LoadItems(dir)
+
+			// Then
+			var fieldErr *FieldError
+			if assert.ErrorAs(t, err, &fieldErr) {
+				assert.Equal(t, filepath.Join(dir, "iron_lance.json"), fieldErr.File)
+				assert.Equal(t, tt.field, fieldErr.Field)
+			}
+		})
+	}
+}
+
+func TestDemoLevelSpawnsFromAssets(t *testing.T) {
+	keepRegistries(t)
+	// Given
+	assert.NoError(t, LoadJobs(filepath.Join("..", "..", JobsDir)))
+	assert.NoError(t, LoadItems(filepath.Join("..", "..", ItemsDir)))
+	project := loadTestProject(t)
+
+	// When
+	b, err := NewLevel(project, project.Levels[0].Identifier, []*Unit{})
+
+	// Then
+	assert.NoError(t, err)
+	assert.NotEmpty(t, b.Units)
+	assert.False(t, b.Cleared())
+}
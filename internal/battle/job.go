@@ -0,0 +1,167 @@
+package battle
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"openFE/internal/combat"
+)
+
+const JobsDir = "assets/demo/jobs"
+
+// Job is the id of a job definition, the file name without .json (Ex: "noble")
+type Job string
+
+type MovementType string
+
+const (
+	INFANTRY MovementType = "infantry"
+	ARMORED  MovementType = "armored"
+	CAVALRY  MovementType = "cavalry"
+	FLIER    MovementType = "flier"
+)
+
+var movementTypes = []MovementType{INFANTRY, ARMORED, CAVALRY, FLIER}
+
+var weaponRanks = []string{"E", "D", "C", "B", "A", "S"}
+
+type JobData struct {
+	Name         string            `json:"name"`
+	Movement     int               `json:"movement"`
+	MovementType MovementType      `json:"movementType"`
+	BaseStats    Stats             `json:"baseStats"`
+	Growths      Stats             `json:"growths"` // Added on top of the unit's personal growths
+	Caps         Stats             `json:"caps"`
+	WeaponRanks  map[string]string `json:"weaponRanks"` // Weapon type -> starting rank, the job can only wield these types
+	Sprite       string            `json:"sprite"`      // Spritesheet path relative to the jobs directory
+	Promotions   []Job             `json:"promotions"`
+}
+
+// Jobs holds every loaded job definition, filled by LoadJobs
+var Jobs = map[Job]*JobData{}
+
+// Data is the job's definition. Spawns and saves check their jobs against Jobs
+// when they're loaded so an unknown job here is a bug, it panics instead of
+// capping every stat to 0.
+func (j Job) Data() *JobData {
+	data, ok := Jobs[j]
+	if !ok {
+		panic(fmt.Sprintf("unknown job %q", j))
+	}
+	return data
+}
+
+// CanWield is true when the job has a rank in weapon type t. Untyped weapons
+// are outside the triangle and anyone can wield them.
+func (data *JobData) CanWield(t combat.WeaponType) bool {
+	_, ok := data.WeaponRanks[string(t)]
+	return ok || t == ""
+}
+
+// LoadJobs reads every *.json file in dir as a job definition.
+// Nothing is registered unless every file is valid.
+func LoadJobs(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no job definitions found in %s", dir)
+	}
+	sort.Strings(files)
+
+	jobs := map[Job]*JobData{}
+	jobFiles := map[Job]string{}
+	for _, file := range files {
+		data, err := loadJob(file)
+		if err != nil {
+			return err
+		}
+		if err := data.validate(file, dir); err != nil {
+			return err
+		}
+		id := Job(strings.TrimSuffix(filepath.Base(file), ".json"))
+		jobs[id] = data
+		jobFiles[id] = file
+	}
+
+	// Promotions can point at any file so they are checked once everything is loaded
+	for id, data := range jobs {
+		for i, promotion := range data.Promotions {
+			if _, ok := jobs[promotion]; !ok {
+				return &FieldError{jobFiles[id], fmt.Sprintf("promotions[%d]", i), fmt.Sprintf("unknown job %q", promotion)}
+			}
+		}
+	}
+
+	Jobs = jobs
+	return nil
+}
+
+func loadJob(file string) (*JobData, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	data := &JobData{}
+	decoder := json.NewDecoder(f)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(data); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return data, nil
+}
+
+func (data *JobData) validate(file, dir string) error {
+	if data.Name == "" {
+		return &FieldError{file, "name", "is required"}
+	}
+	if data.Movement <= 0 {
+		return &FieldError{file, "movement", "must be greater than 0"}
+	}
+	if !slices.Contains(movementTypes, data.MovementType) {
+		return &FieldError{file, "movementType", fmt.Sprintf("must be one of %v", movementTypes)}
+	}
+
+	names := statNames()
+	base := data.BaseStats.fields()
+	growths := data.Growths.fields()
+	for i, c := range data.Caps.fields() {
+		if *c <= 0 {
+			return &FieldError{file, "caps." + names[i], "must be greater than 0"}
+		}
+		if *base[i] < 0 || *base[i] > *c {
+			return &FieldError{file, "baseStats." + names[i], fmt.Sprintf("must be between 0 and the cap (%d)", *c)}
+		}
+		if *growths[i] < 0 {
+			return &FieldError{file, "growths." + names[i], "must not be negative"}
+		}
+	}
+	if data.BaseStats.HP <= 0 {
+		return &FieldError{file, "baseStats.hp", "must be greater than 0"}
+	}
+
+	for weaponType, rank := range data.WeaponRanks {
+		if !slices.Contains(combat.WeaponTypes, combat.WeaponType(weaponType)) {
+			return &FieldError{file, "weaponRanks." + weaponType, fmt.Sprintf("unknown weapon type, must be one of %v", combat.WeaponTypes)}
+		}
+		if !slices.Contains(weaponRanks, rank) {
+			return &FieldError{file, "weaponRanks." + weaponType, fmt.Sprintf("unknown rank %q, must be one of %v", rank, weaponRanks)}
+		}
+	}
+
+	if data.Sprite == "" {
+		return &FieldError{file, "sprite", "is required"}
+	}
+	if _, err := os.Stat(filepath.Join(dir, data.Sprite)); err != nil {
+		return &FieldError{file, "sprite", fmt.Sprintf("can't find %s", data.Sprite)}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package battle
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const validJob = `{
+	"name": "Knight",
+	"movement": 4,
+	"movementType": "armored",
+	"baseStats": { "hp": 20, "str": 5, "def": 8, "con": 12 },
+	"growths": { "hp": 80, "def": 40 },
+	"caps": { "hp": 60, "str": 20, "mag": 20, "skl": 20, "spd": 20, "lck": 30, "def": 30, "res": 20, "con": 20 },
+	"weaponRanks": { "lance": "D" },
+	"sprite": "knight.png",
+	"promotions": %s
+}`
+
+func writeJobDir(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "knight.png"), []byte{}, 0644))
+	for name, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	return dir
+}
+
+func job(promotions string) string {
+	return fmt.Sprintf(validJob, promotions)
+}
+
+func TestLoadJobs(t *testing.T) {
+	keepRegistries(t)
+	// Given
+	dir := writeJobDir(t, map[string]string{
+		"knight.json":  job(`["general"]`),
+		"general.json": job(`[]`),
+	})
+
+	// When
+	err := LoadJobs(dir)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "Knight", Job("knight").Data().Name)
+	assert.Equal(t, ARMORED, Job("knight").Data().MovementType)
+	assert.Equal(t, 8, Job("knight").Data().BaseStats.Def)
+	assert.Equal(t, []Job{"general"}, Job("knight").Data().Promotions)
+}
+
+func TestLoadJobsErrorsNameFileAndField(t *testing.T) {
+	keepRegistries(t)
+	tests := []struct {
+		name    string
+		content string
+		field   string
+	}{
+		{"missing name", strings.Replace(job(`[]`), `"Knight"`, `""`, 1), "name"},
+		{"bad movement type", strings.Replace(job(`[]`), `"armored"`, `"boat"`, 1), "movementType"},
+		{"base over cap", strings.Replace(job(`[]`), `"def": 8`, `"def": 80`, 1), "baseStats.def"},
+		{"unknown weapon", strings.Replace(job(`[]`), `"lance"`, `"spoon"`, 1), "weaponRanks.spoon"},
+		{"staves aren't weapons", strings.Replace(job(`[]`), `"lance"`, `"staff"`, 1), "weaponRanks.staff"},
+		{"bad rank", strings.Replace(job(`[]`), `"D"`, `"Z"`, 1), "weaponRanks.lance"},
+		{"missing sprite", strings.Replace(job(`[]`), `knight.png`, `nope.png`, 1), "sprite"},
+		{"unknown promotion", job(`["paladin"]`), "promotions[0]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			dir := writeJobDir(t, map[string]string{"knight.json": tt.content})
+
+			// When
+			err := LoadJobs(dir)
+
+			// Then
+			var fieldErr *FieldError
+			if assert.ErrorAs(t, err, &fieldErr) {
+				assert.Equal(t, filepath.Join(dir, "knight.json"), fieldErr.File)
+				assert.Equal(t, tt.field, fieldErr.Field)
+			}
+		})
+	}
+}
+
+func TestLoadJobsUnknownField(t *testing.T) {
+	keepRegistries(t)
+	// Given
+	dir := writeJobDir(t, map[string]string{"knight.json": strings.Replace(job(`[]`), `"movement"`, `"move"`, 1)})
+
+	// When
+	err := LoadJobs(dir)
+
+	// Then
+	assert.ErrorContains(t, err, "knight.json")
+	assert.ErrorContains(t, err, "move")
+}
+
+func TestLoadJobsFromAssets(t *testing.T) {
+	keepRegistries(t)
+	assert.NoError(t, LoadJobs(filepath.Join("..", "..", JobsDir)))
+}
@@ -0,0 +1,161 @@
+package battle
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/solarlune/ldtkgo"
+)
+
+// Layers are looked up by their name in ldtk so they can be reordered freely
+const (
+	IntGridLayer  = "IntGrid"  // Terrain of every cell
+	TilesLayer    = "Tiles"    // What the map looks like
+	EntitiesLayer = "Entities" // Unit spawns, see Spawn
+)
+
+// LevelTerrain reads the terrain of every cell from the level's IntGrid layer
+func LevelTerrain(level *ldtkgo.Level) ([][]Terrain, error) {
+	intGrid := level.LayerByIdentifier(IntGridLayer)
+	if intGrid == nil {
+		return nil, fmt.Errorf("level %q has no %s layer", level.Identifier, IntGridLayer)
+	}
+	terrain := make([][]Terrain, intGrid.CellHeight)
+	for y := range terrain {
+		terrain[y] = make([]Terrain, intGrid.CellWidth)
+	}
+	for _, i := range intGrid.IntGrid {
+		terrain[i.ID/intGrid.CellWidth][i.ID%intGrid.CellWidth] = Terrain(i.Value)
+	}
+	return terrain, nil
+}
+
+// NewLevel starts a battle on the level of project called identifier with
+// units (survivors of the previous level) plus the level's own spawns. A unit
+// whose name matches a player spawn takes that spawn, the other units are
+// moved to the closest free cell they can stand on.
+func NewLevel(project *ldtkgo.Project, identifier string, units []*Unit) (*Battle, error) {
+	level := project.LevelByIdentifier(identifier)
+	if level == nil {
+		return nil, fmt.Errorf("unknown level %q", identifier)
+	}
+	terrain, err := LevelTerrain(level)
+	if err != nil {
+		return nil, err
+	}
+	b := New(identifier, terrain, []*Unit{})
+	spawns, err := LevelSpawns(level, b.Width(), b.Height())
+	if err != nil {
+		return nil, err
+	}
+
+	for i, u := range units {
+		if u.id != i {
+			return nil, fmt.Errorf("units[%d]: id %d doesn't match its index", i, u.id)
+		}
+	}
+	units = slices.Clone(units)
+	spawned := []*Unit{}
+	for _, s := range spawns {
+		i := slices.IndexFunc(units, func(u *Unit) bool {
+			return s.Faction == PLAYER && s.Name != "" && u.name == s.Name && !slices.Contains(spawned, u)
+		})
+		if i == -1 {
+			units = append(units, s.Unit(len(units)))
+			i = len(units) - 1
+		}
+		units[i].posXY = s.Pos
+		spawned = append(spawned, units[i])
+	}
+
+	// Spawns are placed first so the units that follow make room for them
+	for _, u := range append(slices.Clone(spawned), units...) {
+		if u.dead || b.UnitAt(u.posXY) == u.id {
+			continue
+		}
+		posXY, ok := u.posXY, true
+		if slices.Contains(spawned, u) {
+			if other := b.UnitAt(posXY); other != EmptyCell {
+				return nil, fmt.Errorf("level %q: unit %d and %d spawn on %v", identifier, other, u.id, posXY)
+			}
+		} else {
+			posXY, ok = b.freeCell(u)
+		}
+		if !ok {
+			return nil, fmt.Errorf("no room for unit %d on level %q", u.id, identifier)
+		}
+		u.posXY = posXY
+		u.posXYHistory = []PosXY{posXY}
+		b.grid[posXY[Y]][posXY[X]].unitId = u.id
+	}
+	b.Units = units
+	return b, nil
+}
+
+// Closest cell to u (by steps, then reading order) that is empty and that u can walk on
+func (b *Battle) freeCell(u *Unit) (PosXY, bool) {
+	movementType := u.rpg.Job.Data().MovementType
+	best, found := PosXY{}, false
+	bestDistance := 0
+	for y := 0; y < b.Height(); y++ {
+		for x := 0; x < b.Width(); x++ {
+			cell := b.grid[y][x]
+			if cell.unitId != EmptyCell || cell.terrain.MoveCost(movementType) == impassable {
+				continue
+			}
+			distance := abs(x-u.posXY[X]) + abs(y-u.posXY[Y])
+			if !found || distance < bestDistance {
+				best, found, bestDistance = PosXY{x, y}, true, distance
+			}
+		}
+	}
+	return best, found
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// NextLevel is the level played once identifier is cleared. Chapters follow
+// the order of the levels in the ldtk project, "" after the last one.
+func NextLevel(project *ldtkgo.Project, identifier string) string {
+	for i, level := range project.Levels {
+		if level.Identifier == identifier && i+1 < len(project.Levels) {
+			return project.Levels[i+1].Identifier
+		}
+	}
+	return ""
+}
+
+// Cleared is true once no enemy of the player is left standing, or on levels
+// with a boss once every boss is dead
+func (b *Battle) Cleared() bool {
+	bosses := slices.ContainsFunc(b.Units, func(u *Unit) bool { return u.boss })
+	for _, u := range b.Units {
+		if !u.dead && !u.faction.IsAlly(PLAYER) && (u.boss || !bosses) {
+			return false
+		}
+	}
+	return true
+}
+
+// Survivors are the living player units, healed and renumbered so they can
+// start the next level
+func (b *Battle) Survivors() []*Unit {
+	units := []*Unit{}
+	for _, u := range b.Units {
+		if u.dead || u.faction != PLAYER {
+			continue
+		}
+		survivor := NewUnit(len(units), u.rpg, u.faction, u.posXY)
+		survivor.name = u.name
+		survivor.items = slices.Clone(u.items)
+		survivor.rpg.HP = survivor.rpg.Stats.HP
+		survivor.SetBehavior(u.behavior, u.guardPos)
+		units = append(units, survivor)
+	}
+	return units
+}
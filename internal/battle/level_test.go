@@ -0,0 +1,104 @@
+package battle
+
+import (
+	"testing"
+
+	"github.com/solarlune/ldtkgo"
+	"github.com/stretchr/testify/assert"
+)
+
+// Project with one level per entry of levels, each row of a level is a string
+// of intgrid values. The tiles layer comes first to check layers are found by name.
+func testProject(levels map[string][]string, order ...string) *ldtkgo.Project {
+	project := &ldtkgo.Project{}
+	for _, identifier := range order {
+		rows := levels[identifier]
+		intGrid := &ldtkgo.Layer{Identifier: IntGridLayer, Type: ldtkgo.LayerTypeIntGrid, CellWidth: len(rows[0]), CellHeight: len(rows)}
+		for y, row := range rows {
+			for x, c := range row {
+				intGrid.IntGrid = append(intGrid.IntGrid, &ldtkgo.Integer{ID: y*len(row) + x, Value: int(c - '0')})
+			}
+		}
+		tiles := &ldtkgo.Layer{Identifier: TilesLayer, Type: ldtkgo.LayerTypeTile}
+		project.Levels = append(project.Levels, &ldtkgo.Level{Identifier: identifier, Layers: []*ldtkgo.Layer{tiles, intGrid}})
+	}
+	return project
+}
+
+func TestLevelTerrainFindsLayerByName(t *testing.T) {
+	// Given
+	project := testProject(map[string][]string{"a": {"01", "23"}}, "a")
+
+	// When
+	sut, err := LevelTerrain(project.Levels[0])
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, [][]Terrain{{PLAINS, WALL}, {FOREST, MOUNTAIN}}, sut)
+}
+
+func TestLevelTerrainMissingLayer(t *testing.T) {
+	// Given
+	level := &ldtkgo.Level{Identifier: "a"}
+
+	// When
+	_, err := LevelTerrain(level)
+
+	// Then
+	assert.Error(t, err)
+}
+
+func TestNextLevelFollowsProjectOrder(t *testing.T) {
+	// Given
+	project := testProject(map[string][]string{"a": {"0"}, "b": {"0"}}, "a", "b")
+
+	// Then
+	assert.Equal(t, "b", NextLevel(project, "a"))
+	assert.Equal(t, "", NextLevel(project, "b"))
+	assert.Equal(t, "", NextLevel(project, "unknown"))
+}
+
+func TestSurvivorsCarryOverToNextLevel(t *testing.T) {
+	// Given a cleared level with a wounded survivor, a dead ally and a dead enemy
+	project := testProject(map[string][]string{
+		"a": {"000", "000"},
+		"b": {"010", "000"},
+	}, "a", "b")
+	dead := testUnit(0, PLAYER, PosXY{0, 0}, 2)
+	p := testUnit(1, PLAYER, PosXY{1, 0}, 2)
+	p.rpg.HP = 3
+	p.rpg.Exp = 40
+	e := testUnit(2, ENEMY, PosXY{2, 0}, 2)
+	b, err := NewLevel(project, "a", []*Unit{dead, p, e})
+	assert.NoError(t, err)
+	b.KillUnit(dead)
+	b.KillUnit(e)
+	assert.True(t, b.Cleared())
+
+	// When
+	next, err := NewLevel(project, NextLevel(project, "a"), b.Survivors())
+
+	// Then the survivor is healed, keeps its exp and is moved off the wall
+	assert.NoError(t, err)
+	assert.Equal(t, "b", next.Level())
+	assert.Len(t, next.Units, 1)
+	u := next.Units[0]
+	assert.Equal(t, 0, u.ID())
+	assert.Equal(t, 20, u.RPG().HP)
+	assert.Equal(t, 40, u.RPG().Exp)
+	assert.Equal(t, PosXY{0, 0}, u.Pos())
+	assert.Equal(t, u.ID(), next.UnitAt(PosXY{0, 0}))
+	assert.Equal(t, PLAYER, next.Phase())
+	assert.Equal(t, 1, next.Turn())
+}
+
+func TestNewLevelUnknownLevel(t *testing.T) {
+	// Given
+	project := testProject(map[string][]string{"a": {"0"}}, "a")
+
+	// When
+	_, err := NewLevel(project, "b", []*Unit{})
+
+	// Then
+	assert.Error(t, err)
+}
@@ -0,0 +1,38 @@
+package battle
+
+import (
+	"os"
+	"testing"
+
+	"openFE/internal/combat"
+)
+
+var testJob = &JobData{
+	Name:         "Test",
+	Movement:     5,
+	MovementType: INFANTRY,
+	Growths:      Stats{HP: 15, Str: 10, Mag: 5, Skl: 10, Spd: 10, Lck: 5, Def: 5, Res: 10},
+	Caps:         Stats{HP: 60, Str: 25, Mag: 22, Skl: 26, Spd: 26, Lck: 30, Def: 24, Res: 25, Con: 20},
+	WeaponRanks:  map[string]string{"lance": "D"},
+}
+
+var testItemData = map[ItemID]*ItemData{
+	"potion":     {Name: "Potion", Uses: 3, Heal: 10},
+	"iron_lance": {Name: "Iron Lance", Uses: 45, Weapon: &combat.Weapon{Name: "Iron Lance", Type: combat.LANCE, Might: 7, Hit: 80, MinRange: 1, MaxRange: 1}},
+	"javelin":    {Name: "Javelin", Uses: 20, Weapon: &combat.Weapon{Name: "Javelin", Type: combat.LANCE, Might: 6, Hit: 65, MinRange: 1, MaxRange: 2}},
+	"iron_sword": {Name: "Iron Sword", Uses: 46, Weapon: &combat.Weapon{Name: "Iron Sword", Type: combat.SWORD, Might: 5, Hit: 90, MinRange: 1, MaxRange: 1}},
+}
+
+// The fixtures are registered once, tests that load their own data put them
+// back with keepRegistries
+func TestMain(m *testing.M) {
+	Jobs = map[Job]*JobData{"test": testJob}
+	Items = testItemData
+	os.Exit(m.Run())
+}
+
+// keepRegistries restores the registries once t is done with them
+func keepRegistries(t *testing.T) {
+	jobs, items, triangle := Jobs, Items, Triangle
+	t.Cleanup(func() { Jobs, Items, Triangle = jobs, items, triangle })
+}
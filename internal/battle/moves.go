@@ -0,0 +1,95 @@
+package battle
+
+import (
+	"slices"
+
+	"openFE/internal/pathfind"
+)
+
+// Reachable is every tile u can end its move on. Allies can be walked through
+// but not stopped on, enemies block the path entirely.
+func (b *Battle) Reachable(u *Unit) []PosXY {
+	_, legalPositions := b.Moves(u)
+	return legalPositions
+}
+
+// Same as Reachable but also keeps the shortest path tree for the move arrow
+func (b *Battle) Moves(u *Unit) (pathfind.Tree, []PosXY) {
+	tree, destinations := pathfind.Moves(u.posXY, b.Width(), b.Height(), u.rpg.Move(), b.TerrainCost(u), b.occupant(u))
+
+	legalPositions := []PosXY{}
+	for _, p := range destinations {
+		legalPositions = append(legalPositions, p)
+	}
+	return tree, legalPositions
+}
+
+func (b *Battle) TerrainCost(u *Unit) pathfind.Cost {
+	movementType := u.rpg.Job.Data().MovementType
+	return func(p [2]int) int {
+		return b.grid[p[Y]][p[X]].terrain.MoveCost(movementType)
+	}
+}
+
+// Tiles u could attack after moving to any of moves, moves themselves are left out
+func (b *Battle) AttackRange(u *Unit, moves []PosXY) []PosXY {
+	from := make([][2]int, len(moves))
+	for i, p := range moves {
+		from[i] = p
+	}
+	minRange, maxRange := u.WeaponRange()
+
+	attackPositions := []PosXY{}
+	for _, p := range pathfind.AttackRange(from, minRange, maxRange, b.Width(), b.Height()) {
+		attackPositions = append(attackPositions, p)
+	}
+	return attackPositions
+}
+
+// Who stands on a tile from u's point of view
+func (b *Battle) occupant(u *Unit) func(p [2]int) pathfind.Occupant {
+	return func(p [2]int) pathfind.Occupant {
+		unitId := b.grid[p[Y]][p[X]].unitId
+		switch {
+		case unitId == EmptyCell:
+			return pathfind.EMPTY
+		case unitId == u.id:
+			return pathfind.SELF
+		case b.Units[unitId].faction.IsAlly(u.faction):
+			return pathfind.ALLY
+		}
+		return pathfind.ENEMY
+	}
+}
+
+// DangerZone is every tile a living enemy of the player could move to or
+// attack next turn. When some of the marked unit ids are alive only those
+// count, once they all died every enemy counts again.
+func (b *Battle) DangerZone(marked []int) []PosXY {
+	marked = slices.DeleteFunc(slices.Clone(marked), func(id int) bool {
+		return id < 0 || id >= len(b.Units) || b.Units[id].dead
+	})
+	seen := map[PosXY]bool{}
+	tiles := []PosXY{}
+	add := func(positions []PosXY) {
+		for _, p := range positions {
+			if !seen[p] {
+				seen[p] = true
+				tiles = append(tiles, p)
+			}
+		}
+	}
+
+	for _, u := range b.Units {
+		if u.dead || u.faction.IsAlly(PLAYER) {
+			continue
+		}
+		if len(marked) > 0 && !slices.Contains(marked, u.id) {
+			continue
+		}
+		moves := b.Reachable(u)
+		add(moves)
+		add(b.AttackRange(u, moves))
+	}
+	return tiles
+}
@@ -0,0 +1,177 @@
+package battle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"openFE/internal/combat"
+)
+
+// Builds a battle without ldtk, '.' plains, '#' wall, 'f' forest, 'm' mountain, '~' water
+func testBattle(rows []string, units []*Unit) *Battle {
+	runes := map[rune]Terrain{'.': PLAINS, '#': WALL, 'f': FOREST, 'm': MOUNTAIN, '~': WATER, 'F': FORT, 'T': THRONE}
+	terrain := make([][]Terrain, len(rows))
+	for y, row := range rows {
+		for _, c := range row {
+			terrain[y] = append(terrain[y], runes[c])
+		}
+	}
+	return New("test", terrain, units)
+}
+
+func testUnit(id int, faction Faction, posXY PosXY, movement int) *Unit {
+	r := testRPG()
+	r.Movement = movement
+	return NewUnit(id, r, faction, posXY)
+}
+
+// [(0 0) | (1 0) | (2 0)]
+// [(0 1) | (1 1) | (2 1)]
+// [(0 2) | (1 2) | (2 2)]
+func TestReachableCells(t *testing.T) {
+	// Given
+	u := testUnit(0, PLAYER, PosXY{1, 1}, 1)
+	b := testBattle([]string{
+		"...",
+		"...",
+		"...",
+	}, []*Unit{u})
+
+	// When
+	sut := b.Reachable(u)
+
+	// Then
+	assert.ElementsMatch(t, []PosXY{{1, 1}, {1, 0}, {0, 1}, {2, 1}, {1, 2}}, sut)
+}
+
+func TestReachableCellsNonSquareMap(t *testing.T) {
+	// Given
+	u := testUnit(0, PLAYER, PosXY{0, 0}, 5)
+	b := testBattle([]string{
+		"......",
+		"......",
+	}, []*Unit{u})
+
+	// When
+	sut := b.Reachable(u)
+
+	// Then
+	assert.Contains(t, sut, PosXY{5, 0})
+	assert.Contains(t, sut, PosXY{4, 1})
+	assert.NotContains(t, sut, PosXY{5, 1})
+}
+
+func TestReachableCellsTerrain(t *testing.T) {
+	// Given
+	u := testUnit(0, PLAYER, PosXY{0, 0}, 3)
+	b := testBattle([]string{
+		".f..",
+		"#~..",
+	}, []*Unit{u})
+
+	// When
+	sut := b.Reachable(u)
+
+	// Then
+	assert.ElementsMatch(t, []PosXY{{0, 0}, {1, 0}, {2, 0}}, sut)
+}
+
+func TestReachableCellsPassAlliesBlockEnemies(t *testing.T) {
+	// Given
+	u := testUnit(0, PLAYER, PosXY{0, 0}, 2)
+	ally := testUnit(1, OTHER, PosXY{1, 0}, 2)
+	enemy := testUnit(2, ENEMY, PosXY{0, 1}, 2)
+	b := testBattle([]string{
+		"....",
+		"....",
+		"....",
+	}, []*Unit{u, ally, enemy})
+
+	// When
+	sut := b.Reachable(u)
+
+	// Then
+	// Walks through the ally to (2, 0) and (1, 1) but can't stop on it,
+	// the enemy blocks the path down to (0, 2)
+	assert.ElementsMatch(t, []PosXY{{0, 0}, {2, 0}, {1, 1}}, sut)
+}
+
+func TestAttackableCells(t *testing.T) {
+	// Given
+	u := testUnit(0, PLAYER, PosXY{0, 0}, 1)
+	u.rpg.Weapon = combat.Weapon{MinRange: 1, MaxRange: 2}
+	b := testBattle([]string{
+		"....",
+		"....",
+		"....",
+	}, []*Unit{u})
+	moves := b.Reachable(u)
+
+	// When
+	sut := b.AttackRange(u, moves)
+
+	// Then
+	assert.ElementsMatch(t, []PosXY{{2, 0}, {3, 0}, {1, 1}, {2, 1}, {0, 2}, {1, 2}}, sut)
+}
+
+func TestWeaponRangeCoversCarriedWeapons(t *testing.T) {
+	tests := []struct {
+		name     string
+		items    []ItemID
+		min, max int
+	}{
+		{"unarmed", []ItemID{"potion"}, 0, 0},
+		{"one weapon", []ItemID{"iron_lance"}, 1, 1},
+		{"javelin carried behind the lance", []ItemID{"iron_lance", "potion", "javelin"}, 1, 2},
+		{"sword it can't wield", []ItemID{"iron_sword"}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			u := testUnit(0, PLAYER, PosXY{0, 0}, 1)
+			for _, id := range tt.items {
+				assert.True(t, u.GiveItem(id))
+			}
+
+			// When
+			minRange, maxRange := u.WeaponRange()
+
+			// Then
+			assert.Equal(t, tt.min, minRange)
+			assert.Equal(t, tt.max, maxRange)
+		})
+	}
+}
+
+func TestDangerZoneUnionOfEnemies(t *testing.T) {
+	// Given
+	p := testUnit(0, PLAYER, PosXY{0, 0}, 1)
+	a := testUnit(1, ENEMY, PosXY{2, 0}, 1)
+	c := testUnit(2, ENEMY, PosXY{6, 0}, 1)
+	for _, e := range []*Unit{a, c} {
+		e.rpg.Weapon = combat.Weapon{MinRange: 1, MaxRange: 1}
+	}
+	b := testBattle([]string{"........"}, []*Unit{p, a, c})
+
+	// When
+	sut := b.DangerZone(nil)
+
+	// Then
+	assert.ElementsMatch(t, []PosXY{{0, 0}, {1, 0}, {2, 0}, {3, 0}, {4, 0}, {5, 0}, {6, 0}, {7, 0}}, sut)
+
+	// When only one enemy is marked
+	sut = b.DangerZone([]int{c.id})
+
+	// Then
+	assert.ElementsMatch(t, []PosXY{{4, 0}, {5, 0}, {6, 0}, {7, 0}}, sut)
+
+	// When the marked enemy died
+	b.KillUnit(c)
+	sut = b.DangerZone([]int{c.id})
+
+	// Then the zone falls back to every enemy left
+	assert.ElementsMatch(t, b.DangerZone(nil), sut)
+	assert.NotEmpty(t, sut)
+}
@@ -0,0 +1,97 @@
+package battle
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/solarlune/ldtkgo"
+
+	"openFE/internal/rng"
+)
+
+const ReplayVersion = 1
+
+// Replay is the battle it started from (rng state included) and every command
+// applied since. Replaying the commands always ends on the same state.
+type Replay struct {
+	Version  int       `json:"version"`
+	Start    SaveData  `json:"start"`
+	Commands []Command `json:"commands"`
+	Hash     string    `json:"hash"` // Hash of the SaveData once every command is applied
+
+	migrated bool // Start was saved by an older version, Hash was taken on the old format
+}
+
+func (r *Replay) Write(path string) error {
+	return writeJSON(path, r)
+}
+
+// ReadReplay decodes a replay, its start goes through the same migrations as saves
+func ReadReplay(path string) (*Replay, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	raw := struct {
+		Replay
+		Start json.RawMessage `json:"start"`
+	}{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	r := &raw.Replay
+	if r.Version != ReplayVersion {
+		return nil, fmt.Errorf("%s: replay version %d isn't supported", path, r.Version)
+	}
+	saved := struct {
+		Version int `json:"version"`
+	}{}
+	if err := json.Unmarshal(raw.Start, &saved); err != nil {
+		return nil, fmt.Errorf("%s: start: %w", path, err)
+	}
+	start, err := decodeSave(raw.Start)
+	if err != nil {
+		return nil, fmt.Errorf("%s: start: %w", path, err)
+	}
+	r.Start = *start
+	r.migrated = saved.Version < SaveVersion
+	return r, nil
+}
+
+// Simulate replays every command and returns the battle and rng it ends on
+func (r *Replay) Simulate(project *ldtkgo.Project) (*Battle, *rng.RNG, error) {
+	b, err := r.Start.Battle(project)
+	if err != nil {
+		return nil, nil, err
+	}
+	roller := rng.New(r.Start.Rng)
+	for i, cmd := range r.Commands {
+		if _, err := b.Apply(cmd, roller); err != nil {
+			return b, roller, fmt.Errorf("command %d: %w", i, err)
+		}
+	}
+	return b, roller, nil
+}
+
+// Migrated is true when the replay was recorded by an older version, its hash
+// can't be checked anymore
+func (r *Replay) Migrated() bool {
+	return r.migrated
+}
+
+// Verify re-simulates the replay and checks it ends on the recorded hash,
+// migrated replays only have to play through
+func (r *Replay) Verify(project *ldtkgo.Project) error {
+	b, roller, err := r.Simulate(project)
+	if err != nil {
+		return err
+	}
+	if r.migrated {
+		return nil
+	}
+	if hash := b.SaveData(roller.State()).Hash(); hash != r.Hash {
+		return fmt.Errorf("replay ended on state %s, expected %s", hash, r.Hash)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package battle
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"openFE/internal/combat"
+	"openFE/internal/rng"
+)
+
+// Plays a short fight and records it the same way the game does
+func recordFight(t *testing.T) *Replay {
+	project := loadTestProject(t)
+	p := testUnit(0, PLAYER, PosXY{0, 1}, 5)
+	e := testUnit(1, ENEMY, PosXY{2, 3}, 5)
+	for _, u := range []*Unit{p, e} {
+		u.rpg.Weapon = combat.Weapon{Name: "Iron Sword", Might: 5, Hit: 70, Crit: 10, MinRange: 1, MaxRange: 1}
+	}
+	b := testLevelBattle(t, project, p, e)
+	roller := rng.New(42)
+	r := &Replay{Version: ReplayVersion, Start: b.SaveData(roller.State())}
+	for _, cmd := range []Command{
+		{Type: MOVE, UnitID: 0, To: PosXY{1, 3}},
+		{Type: ATTACK, UnitID: 0, TargetID: 1},
+		{Type: ATTACK, UnitID: 1, TargetID: 0},
+	} {
+		_, err := b.Apply(cmd, roller)
+		assert.NoError(t, err)
+		r.Commands = append(r.Commands, cmd)
+	}
+	r.Hash = b.SaveData(roller.State()).Hash()
+	return r
+}
+
+func TestReplayVerify(t *testing.T) {
+	// Given
+	project := loadTestProject(t)
+	path := filepath.Join(t.TempDir(), "replay.json")
+	assert.NoError(t, recordFight(t).Write(path))
+
+	// When
+	replay, err := ReadReplay(path)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Len(t, replay.Commands, 3)
+	assert.NoError(t, replay.Verify(project))
+}
+
+func TestReplayVerifyCatchesDivergence(t *testing.T) {
+	// Given
+	project := loadTestProject(t)
+	replay := recordFight(t)
+
+	// When the battle started with another seed
+	replay.Start.Rng += 1
+	err := replay.Verify(project)
+
+	// Then
+	assert.Error(t, err)
+}
+
+func TestReadReplayMigratesOldStart(t *testing.T) {
+	// Given a replay recorded before units could be saved mid action
+	project := loadTestProject(t)
+	path := filepath.Join(t.TempDir(), "replay.json")
+	replay := recordFight(t)
+	replay.Start.Version = 4
+	assert.NoError(t, replay.Write(path))
+
+	// When
+	loaded, err := ReadReplay(path)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, SaveVersion, loaded.Start.Version)
+	assert.True(t, loaded.Migrated())
+	assert.NoError(t, loaded.Verify(project))
+}
+
+func TestReadReplayRejectsNewerStart(t *testing.T) {
+	// Given
+	path := filepath.Join(t.TempDir(), "replay.json")
+	replay := recordFight(t)
+	replay.Start.Version = SaveVersion + 1
+	assert.NoError(t, replay.Write(path))
+
+	// When
+	_, err := ReadReplay(path)
+
+	// Then
+	assert.Error(t, err)
+}
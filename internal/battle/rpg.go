@@ -0,0 +1,153 @@
+package battle
+
+import "openFE/internal/combat"
+
+const (
+	MaxLevel    = 20
+	ExpPerLevel = 100
+)
+
+// Stats is used for base stats, growth rates (in %) and caps
+type Stats struct {
+	HP  int `json:"hp"`
+	Str int `json:"str"`
+	Mag int `json:"mag"`
+	Skl int `json:"skl"`
+	Spd int `json:"spd"`
+	Lck int `json:"lck"`
+	Def int `json:"def"`
+	Res int `json:"res"`
+	Con int `json:"con"`
+}
+
+// Order matters, level up rolls happen in this order so a seed always gives the same result
+func (s *Stats) fields() []*int {
+	return []*int{&s.HP, &s.Str, &s.Mag, &s.Skl, &s.Spd, &s.Lck, &s.Def, &s.Res, &s.Con}
+}
+
+// Same order as fields, used in error messages
+func statNames() []string {
+	return []string{"hp", "str", "mag", "skl", "spd", "lck", "def", "res", "con"}
+}
+
+func (s Stats) Add(other Stats) Stats {
+	sum := s
+	o := other.fields()
+	for i, f := range sum.fields() {
+		*f += *o[i]
+	}
+	return sum
+}
+
+func (s Stats) Cap(caps Stats) Stats {
+	capped := s
+	c := caps.fields()
+	for i, f := range capped.fields() {
+		*f = min(*f, *c[i])
+	}
+	return capped
+}
+
+type RPG struct {
+	Job           Job           `json:"job"`
+	Movement      int           `json:"movement"`
+	MovementBonus int           `json:"movementBonus"` // Boots, skills etc, added on top of Movement
+	Level         int           `json:"level"`
+	Exp           int           `json:"exp"`
+	HP            int           `json:"hp"` // Current HP, max HP is Stats.HP
+	Stats         Stats         `json:"stats"`
+	Growths       Stats         `json:"growths"` // Personal growth rates in %
+	Weapon        combat.Weapon `json:"weapon"`  // Copy of the equipped item's weapon, units without items keep what they were given
+}
+
+// NewRPG starts a level 1 unit with the job's base stats and movement. Like
+// Job.Data it panics on a job missing from Jobs, check ids read from files first.
+func NewRPG(job Job, growths Stats) RPG {
+	data := job.Data()
+	return RPG{
+		Job:      job,
+		Movement: data.Movement,
+		Level:    1,
+		HP:       data.BaseStats.HP,
+		Stats:    data.BaseStats,
+		Growths:  growths,
+	}
+}
+
+// How many move points the unit gets this turn
+func (r *RPG) Move() int {
+	return max(0, r.Movement+r.MovementBonus)
+}
+
+// Personal growths plus the job's growths
+func (r *RPG) GrowthRates() Stats {
+	return r.Growths.Add(r.Job.Data().Growths)
+}
+
+// LevelUp rolls every stat once against its growth rate. Growths over 100 give
+// a guaranteed point for every full 100. Returns what was gained after caps.
+func (r *RPG) LevelUp(roller combat.Roller) Stats {
+	if r.Level >= MaxLevel {
+		return Stats{}
+	}
+
+	growths := r.GrowthRates()
+	gains := Stats{}
+	g := growths.fields()
+	for i, f := range gains.fields() {
+		*f = *g[i] / 100
+		if roller.Intn(100) < *g[i]%100 {
+			*f += 1
+		}
+	}
+
+	before := r.Stats
+	r.Stats = r.Stats.Add(gains).Cap(r.Job.Data().Caps)
+	gained := r.Stats.Add(before.negate())
+
+	r.Level += 1
+	r.HP += gained.HP
+	return gained
+}
+
+// GainExp adds experience and levels up for every ExpPerLevel reached.
+// Returns the gains of each level up in order.
+func (r *RPG) GainExp(exp int, roller combat.Roller) []Stats {
+	levelUps := []Stats{}
+	if r.Level >= MaxLevel {
+		return levelUps
+	}
+
+	r.Exp += exp
+	for r.Exp >= ExpPerLevel && r.Level < MaxLevel {
+		r.Exp -= ExpPerLevel
+		levelUps = append(levelUps, r.LevelUp(roller))
+	}
+	if r.Level >= MaxLevel {
+		r.Exp = 0
+	}
+	return levelUps
+}
+
+// AutoLevel raises a level 1 unit to level with its average growths, so units
+// placed in a level have the same stats every time it is played
+func (r *RPG) AutoLevel(level int) {
+	level = min(max(level, 1), MaxLevel)
+	growths := r.GrowthRates()
+	gains := Stats{}
+	g := growths.fields()
+	for i, f := range gains.fields() {
+		*f = *g[i] * (level - r.Level) / 100
+	}
+	r.Stats = r.Stats.Add(gains).Cap(r.Job.Data().Caps)
+	r.HP = r.Stats.HP
+	r.Level = max(level, r.Level)
+}
+
+func (s Stats) negate() Stats {
+	n := s
+	for _, f := range n.fields() {
+		*f = -*f
+	}
+	return n
+}
@@ -0,0 +1,117 @@
+package battle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"openFE/internal/rng"
+)
+
+func testRPG() RPG {
+	return RPG{
+		Job:     "test",
+		Level:   1,
+		HP:      20,
+		Stats:   Stats{HP: 20, Str: 6, Mag: 1, Skl: 5, Spd: 7, Lck: 4, Def: 4, Res: 2, Con: 7},
+		Growths: Stats{HP: 80, Str: 45, Mag: 10, Skl: 50, Spd: 40, Lck: 45, Def: 30, Res: 35},
+	}
+}
+
+func TestUnknownJobPanics(t *testing.T) {
+	assert.PanicsWithValue(t, `unknown job "typo"`, func() { NewRPG("typo", Stats{}) })
+}
+
+func TestGrowthRatesIncludeJob(t *testing.T) {
+	// Given
+	r := testRPG()
+
+	// When
+	sut := r.GrowthRates()
+
+	// Then
+	assert.Equal(t, Stats{HP: 95, Str: 55, Mag: 15, Skl: 60, Spd: 50, Lck: 50, Def: 35, Res: 45}, sut)
+}
+
+func TestLevelUpIsReproducibleFromSeed(t *testing.T) {
+	// Given
+	a := testRPG()
+	b := testRPG()
+
+	// When
+	gainsA := a.GainExp(500, rng.New(1234))
+	gainsB := b.GainExp(500, rng.New(1234))
+
+	// Then
+	assert.Len(t, gainsA, 5)
+	assert.Equal(t, gainsA, gainsB)
+	assert.Equal(t, a, b)
+	assert.Equal(t, 6, a.Level)
+}
+
+func TestLevelUpRespectsCaps(t *testing.T) {
+	// Given
+	r := testRPG()
+	r.Growths = Stats{HP: 300, Str: 300, Mag: 300, Skl: 300, Spd: 300, Lck: 300, Def: 300, Res: 300, Con: 300}
+
+	// When
+	r.GainExp(ExpPerLevel*(MaxLevel-1), rng.New(1))
+
+	// Then
+	assert.Equal(t, testJob.Caps, r.Stats)
+	assert.Equal(t, MaxLevel, r.Level)
+	assert.Equal(t, 0, r.Exp)
+}
+
+func TestLevelUpGuaranteedGrowths(t *testing.T) {
+	// Given
+	r := testRPG()
+	r.Growths = Stats{HP: 100 - testJob.Growths.HP}
+
+	// When
+	gains := r.LevelUp(rng.New(99))
+
+	// Then
+	assert.Equal(t, 1, gains.HP)
+	assert.Equal(t, 21, r.Stats.HP)
+	assert.Equal(t, 21, r.HP)
+}
+
+func TestNoLevelUpAtMaxLevel(t *testing.T) {
+	// Given
+	r := testRPG()
+	r.Level = MaxLevel
+
+	// When
+	gains := r.GainExp(250, rng.New(5))
+
+	// Then
+	assert.Empty(t, gains)
+	assert.Equal(t, 0, r.Exp)
+}
+
+func TestAutoLevelUsesAverageGrowths(t *testing.T) {
+	// Given
+	r := testRPG()
+
+	// When
+	r.AutoLevel(11)
+
+	// Then
+	assert.Equal(t, 11, r.Level)
+	assert.Equal(t, Stats{HP: 29, Str: 11, Mag: 2, Skl: 11, Spd: 12, Lck: 9, Def: 7, Res: 6, Con: 7}, r.Stats)
+	assert.Equal(t, 29, r.HP)
+}
+
+func TestMoveIncludesBonus(t *testing.T) {
+	// Given
+	r := testRPG()
+	r.Movement = 5
+	r.MovementBonus = 2
+
+	// When / Then
+	assert.Equal(t, 7, r.Move())
+
+	r.MovementBonus = -9
+	assert.Equal(t, 0, r.Move())
+}
@@ -0,0 +1,325 @@
+package battle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/solarlune/ldtkgo"
+
+	"openFE/internal/ai"
+	"openFE/internal/combat"
+)
+
+// Bump when the format changes and add a migration from the previous version
+const SaveVersion = 5
+
+// migration upgrades a decoded save by one version, keyed by the version it upgrades from.
+// Saves are migrated as raw json so old files never have to match the current structs.
+type migration func(save map[string]any) error
+
+var migrations = map[int]migration{
+	// Units got a name, a boss flag and items when they started spawning from ldtk
+	1: func(save map[string]any) error {
+		units, ok := save["units"].([]any)
+		if !ok {
+			return fmt.Errorf("units isn't a list")
+		}
+		for _, u := range units {
+			unit, ok := u.(map[string]any)
+			if !ok {
+				return fmt.Errorf("unit isn't an object")
+			}
+			unit["name"] = ""
+			unit["boss"] = false
+			unit["items"] = []any{}
+		}
+		return nil
+	},
+	// Carried items started wearing out, they are saved with the uses they have left.
+	// Reads the item definitions so LoadItems has to run first.
+	2: func(save map[string]any) error {
+		units, ok := save["units"].([]any)
+		if !ok {
+			return fmt.Errorf("units isn't a list")
+		}
+		for _, u := range units {
+			unit, ok := u.(map[string]any)
+			if !ok {
+				return fmt.Errorf("unit isn't an object")
+			}
+			ids, ok := unit["items"].([]any)
+			if !ok {
+				return fmt.Errorf("items isn't a list")
+			}
+			items := []any{}
+			for _, id := range ids {
+				data, ok := Items[ItemID(fmt.Sprint(id))]
+				if !ok {
+					return fmt.Errorf("unknown item %q", id)
+				}
+				items = append(items, map[string]any{"id": id, "uses": data.Uses})
+			}
+			unit["items"] = items
+		}
+		return nil
+	},
+	// Weapons got a type for the weapon triangle, equipped ones take it from the item they were made from
+	3: func(save map[string]any) error {
+		units, ok := save["units"].([]any)
+		if !ok {
+			return fmt.Errorf("units isn't a list")
+		}
+		types := map[string]combat.WeaponType{}
+		for _, data := range Items {
+			if data.Weapon != nil {
+				types[data.Name] = data.Weapon.Type
+			}
+		}
+		for _, u := range units {
+			unit, ok := u.(map[string]any)
+			if !ok {
+				return fmt.Errorf("unit isn't an object")
+			}
+			rpg, ok := unit["rpg"].(map[string]any)
+			if !ok {
+				return fmt.Errorf("rpg isn't an object")
+			}
+			weapon, ok := rpg["weapon"].(map[string]any)
+			if !ok {
+				continue // Unarmed
+			}
+			weapon["type"] = types[fmt.Sprint(weapon["name"])]
+		}
+		return nil
+	},
+	// Saves can be taken mid action, units remember whether they moved and where from
+	4: func(save map[string]any) error {
+		units, ok := save["units"].([]any)
+		if !ok {
+			return fmt.Errorf("units isn't a list")
+		}
+		for _, u := range units {
+			unit, ok := u.(map[string]any)
+			if !ok {
+				return fmt.Errorf("unit isn't an object")
+			}
+			unit["moved"] = false
+			unit["from"] = unit["pos"]
+		}
+		return nil
+	},
+}
+
+// SaveData is everything needed to rebuild a battle, replays and hashes can
+// catch a unit mid action
+type SaveData struct {
+	Version int        `json:"version"`
+	Level   string     `json:"level"` // ldtk level identifier, the map itself isn't saved
+	Turn    int        `json:"turn"`
+	Phase   Faction    `json:"phase"`
+	Rng     uint64     `json:"rng"`
+	Units   []UnitSave `json:"units"`
+}
+
+type UnitSave struct {
+	ID       int         `json:"id"`
+	Name     string      `json:"name"`
+	Faction  Faction     `json:"faction"`
+	Pos      PosXY       `json:"pos"`
+	RPG      RPG         `json:"rpg"`
+	Moved    bool        `json:"moved"`
+	From     PosXY       `json:"from"` // Where the unit moved from, Pos if it didn't move
+	Waited   bool        `json:"waited"`
+	Dead     bool        `json:"dead"`
+	Behavior ai.Behavior `json:"behavior"`
+	GuardPos PosXY       `json:"guardPos"`
+	Boss     bool        `json:"boss"`
+	Items    []Item      `json:"items"`
+}
+
+// SaveData snapshots the battle, rng is the state of the rng it's played with
+func (b *Battle) SaveData(rng uint64) SaveData {
+	data := SaveData{
+		Version: SaveVersion,
+		Level:   b.level,
+		Turn:    b.turn,
+		Phase:   b.phase,
+		Rng:     rng,
+		Units:   []UnitSave{},
+	}
+	for _, u := range b.Units {
+		data.Units = append(data.Units, UnitSave{
+			ID:       u.id,
+			Name:     u.name,
+			Faction:  u.faction,
+			Pos:      u.posXY,
+			RPG:      u.rpg,
+			Moved:    u.moved,
+			From:     u.from(),
+			Waited:   u.waited,
+			Dead:     u.dead,
+			Behavior: u.behavior,
+			GuardPos: u.guardPos,
+			Boss:     u.boss,
+			Items:    slices.Clone(u.items),
+		})
+	}
+	return data
+}
+
+// Hash fingerprints everything the save holds, two battles with the same
+// hash play out the same from there
+func (data SaveData) Hash() string {
+	b, err := json.Marshal(data)
+	if err != nil {
+		panic(err) // SaveData is plain structs, this can't happen
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(b))
+}
+
+// Write saves data to path, creating the directory if needed
+func (data SaveData) Write(path string) error {
+	return writeJSON(path, data)
+}
+
+func writeJSON(path string, v any) error {
+	b, err := json.MarshalIndent(v, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
+
+// ReadSave decodes a save file, migrating it to SaveVersion first
+func ReadSave(path string) (*SaveData, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := decodeSave(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return data, nil
+}
+
+// Decodes the json of a save, migrating it to SaveVersion first
+func decodeSave(b []byte) (*SaveData, error) {
+	raw := map[string]any{}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber() // Keeps the rng state exact, float64 would round it
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+	if err := migrate(raw, migrations, SaveVersion); err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	data := &SaveData{}
+	if err := json.Unmarshal(b, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func migrate(raw map[string]any, migrations map[int]migration, to int) error {
+	number, ok := raw["version"].(json.Number)
+	if !ok {
+		return fmt.Errorf("missing save version")
+	}
+	version, err := number.Int64()
+	if err != nil || version < 1 {
+		return fmt.Errorf("invalid save version %s", number)
+	}
+	if int(version) > to {
+		return fmt.Errorf("save version %d is newer than this game supports (%d)", version, to)
+	}
+
+	for v := int(version); v < to; v++ {
+		m, ok := migrations[v]
+		if !ok {
+			return fmt.Errorf("no migration from save version %d", v)
+		}
+		if err := m(raw); err != nil {
+			return fmt.Errorf("migrating save version %d: %w", v, err)
+		}
+		raw["version"] = json.Number(fmt.Sprint(v + 1))
+	}
+	return nil
+}
+
+// Battle rebuilds the battle on the saved level of project and puts the units back
+func (data *SaveData) Battle(project *ldtkgo.Project) (*Battle, error) {
+	if project == nil || project.LevelByIdentifier(data.Level) == nil {
+		return nil, fmt.Errorf("unknown level %q", data.Level)
+	}
+	terrain, err := LevelTerrain(project.LevelByIdentifier(data.Level))
+	if err != nil {
+		return nil, err
+	}
+	b := New(data.Level, terrain, []*Unit{})
+
+	units := []*Unit{}
+	for i, s := range data.Units {
+		if s.ID != i {
+			return nil, fmt.Errorf("units[%d]: id %d doesn't match its index", i, s.ID)
+		}
+		if _, ok := Jobs[s.RPG.Job]; !ok {
+			return nil, fmt.Errorf("units[%d]: unknown job %q", i, s.RPG.Job)
+		}
+		u := NewUnit(s.ID, s.RPG, s.Faction, s.Pos)
+		if len(s.Items) > MaxItems {
+			return nil, fmt.Errorf("units[%d]: carries more than %d items", i, MaxItems)
+		}
+		for j, item := range s.Items {
+			data, ok := Items[item.ID]
+			if !ok {
+				return nil, fmt.Errorf("units[%d]: items[%d]: unknown item %q", i, j, item.ID)
+			}
+			if item.Uses < 1 || item.Uses > data.Uses {
+				return nil, fmt.Errorf("units[%d]: items[%d]: uses must be between 1 and %d", i, j, data.Uses)
+			}
+		}
+		u.name = s.Name
+		u.boss = s.Boss
+		u.items = s.Items
+		u.moved = s.Moved
+		if s.Moved {
+			u.posXYHistory = []PosXY{s.From, s.Pos}
+		}
+		u.waited = s.Waited
+		u.dead = s.Dead
+		u.SetBehavior(s.Behavior, s.GuardPos)
+		units = append(units, u)
+
+		if u.dead {
+			continue
+		}
+		if !b.InBounds(u.posXY) {
+			return nil, fmt.Errorf("units[%d]: position %v is off the map", i, u.posXY)
+		}
+		if other := b.UnitAt(u.posXY); other != EmptyCell {
+			return nil, fmt.Errorf("units[%d]: position %v is taken by unit %d", i, u.posXY, other)
+		}
+		b.grid[u.posXY[Y]][u.posXY[X]].unitId = u.id
+	}
+	b.Units = units
+	if slices.ContainsFunc(units, func(u *Unit) bool { return u.moved && !u.waited && !u.dead && u != b.MidAction() }) {
+		return nil, fmt.Errorf("more than one unit is mid action")
+	}
+	b.turn = data.Turn
+	b.phase = data.Phase
+	return b, nil
+}
@@ -0,0 +1,246 @@
+package battle
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/solarlune/ldtkgo"
+	"github.com/stretchr/testify/assert"
+
+	"openFE/internal/ai"
+	"openFE/internal/combat"
+)
+
+func loadTestProject(t *testing.T) *ldtkgo.Project {
+	project, err := ldtkgo.Open(filepath.Join("..", "..", "assets", "demo", "8x8.ldtk"))
+	assert.NoError(t, err)
+	return project
+}
+
+// Battle on the demo map's first level with units instead of its spawns
+func testLevelBattle(t *testing.T, project *ldtkgo.Project, units ...*Unit) *Battle {
+	level := project.Levels[0]
+	terrain, err := LevelTerrain(level)
+	assert.NoError(t, err)
+	return New(level.Identifier, terrain, units)
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	// Given a battle halfway through
+	project := loadTestProject(t)
+	p := testUnit(0, PLAYER, PosXY{0, 1}, 5)
+	p.rpg.Weapon = combat.Weapon{Name: "Iron Sword", Might: 5, Hit: 90, MinRange: 1, MaxRange: 1}
+	p.rpg.HP = 11
+	e := testUnit(1, ENEMY, PosXY{1, 0}, 5)
+	e.SetBehavior(ai.GUARD, PosXY{1, 0})
+	dead := testUnit(2, ENEMY, PosXY{3, 3}, 5)
+	b := testLevelBattle(t, project, p, e, dead)
+	b.KillUnit(dead)
+	b.Wait(p)
+	path := filepath.Join(t.TempDir(), "slot1.json")
+
+	// When
+	assert.NoError(t, b.SaveData(7).Write(path))
+	data, err := ReadSave(path)
+	assert.NoError(t, err)
+	loaded, err := data.Battle(project)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), data.Rng)
+	assert.Equal(t, b.SaveData(7), loaded.SaveData(7))
+	assert.Equal(t, ENEMY, loaded.Phase())
+	assert.Equal(t, 11, loaded.Units[p.id].rpg.HP)
+	assert.Equal(t, p.id, loaded.UnitAt(PosXY{0, 1}))
+	assert.Equal(t, EmptyCell, loaded.UnitAt(PosXY{3, 3}))
+	assert.Equal(t, b.grid, loaded.grid)
+}
+
+func TestSaveRejectsUnknownLevel(t *testing.T) {
+	// Given
+	project := loadTestProject(t)
+	data := testLevelBattle(t, project).SaveData(1)
+	data.Level = "Missing"
+
+	// When
+	_, err := data.Battle(project)
+
+	// Then
+	assert.ErrorContains(t, err, "unknown level")
+}
+
+func TestReadSaveRejectsNewerVersion(t *testing.T) {
+	// Given
+	path := filepath.Join(t.TempDir(), "slot1.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"version": 99}`), 0o644))
+
+	// When
+	_, err := ReadSave(path)
+
+	// Then
+	assert.ErrorContains(t, err, "newer")
+}
+
+func TestMigrateUpgradesOneVersionAtATime(t *testing.T) {
+	// Given a v1 save and two made up migrations
+	raw := map[string]any{}
+	decoder := json.NewDecoder(strings.NewReader(`{"version": 1, "rng": 18446744073709551615, "units": []}`))
+	decoder.UseNumber()
+	assert.NoError(t, decoder.Decode(&raw))
+	fake := map[int]migration{
+		1: func(save map[string]any) error {
+			save["level"] = "Level_0"
+			return nil
+		},
+		2: func(save map[string]any) error {
+			save["turn"] = json.Number("3")
+			return nil
+		},
+	}
+
+	// When
+	err := migrate(raw, fake, 3)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, json.Number("3"), raw["version"])
+	assert.Equal(t, "Level_0", raw["level"])
+	assert.Equal(t, json.Number("18446744073709551615"), raw["rng"])
+
+	// When a step is missing
+	raw["version"] = json.Number("1")
+	err = migrate(raw, map[int]migration{1: fake[1]}, 3)
+
+	// Then
+	assert.ErrorContains(t, err, "no migration from save version 2")
+}
+
+func TestReadSaveMigratesVersion1(t *testing.T) {
+	// Given a save from before units had names and items
+	path := filepath.Join(t.TempDir(), "slot1.json")
+	v1 := `{"version": 1, "level": "Level_0", "turn": 2, "phase": 0, "rng": 5, "units": [{"id": 0, "faction": 0, "pos": [0, 1], "rpg": {}}]}`
+	assert.NoError(t, os.WriteFile(path, []byte(v1), 0o644))
+
+	// When
+	data, err := ReadSave(path)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, SaveVersion, data.Version)
+	assert.Equal(t, "", data.Units[0].Name)
+	assert.Equal(t, []Item{}, data.Units[0].Items)
+	assert.Equal(t, PosXY{0, 1}, data.Units[0].Pos)
+}
+
+func TestReadSaveMigratesVersion2Items(t *testing.T) {
+	// Given a save from before items wore out
+	path := filepath.Join(t.TempDir(), "slot1.json")
+	v2 := `{"version": 2, "level": "Level_0", "rng": 5, "units": [{"id": 0, "pos": [0, 1], "rpg": {}, "items": ["iron_lance", "potion"]}]}`
+	assert.NoError(t, os.WriteFile(path, []byte(v2), 0o644))
+
+	// When
+	data, err := ReadSave(path)
+
+	// Then the items are as good as new
+	assert.NoError(t, err)
+	assert.Equal(t, []Item{{"iron_lance", 45}, {"potion", 3}}, data.Units[0].Items)
+}
+
+func TestSaveRejectsWornOutItems(t *testing.T) {
+	// Given
+	project := loadTestProject(t)
+	data := testLevelBattle(t, project, testUnit(0, PLAYER, PosXY{0, 1}, 5)).SaveData(1)
+	data.Units[0].Items = []Item{{"potion", 0}}
+
+	// When
+	_, err := data.Battle(project)
+
+	// Then
+	assert.ErrorContains(t, err, "uses must be between 1 and 3")
+}
+
+func TestReadSaveMigratesVersion3WeaponType(t *testing.T) {
+	// Given a save from before the weapon triangle
+	path := filepath.Join(t.TempDir(), "slot1.json")
+	v3 := `{"version": 3, "level": "Level_0", "rng": 5, "units": [
+		{"id": 0, "pos": [0, 1], "rpg": {"weapon": {"name": "Iron Lance", "might": 7}}, "items": []},
+		{"id": 1, "pos": [1, 1], "rpg": {"weapon": {"name": "Rusty Spoon", "might": 1}}, "items": []}
+	]}`
+	assert.NoError(t, os.WriteFile(path, []byte(v3), 0o644))
+
+	// When
+	data, err := ReadSave(path)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, combat.LANCE, data.Units[0].RPG.Weapon.Type)
+	assert.Equal(t, 7, data.Units[0].RPG.Weapon.Might)
+	assert.Equal(t, combat.WeaponType(""), data.Units[1].RPG.Weapon.Type)
+}
+
+func TestReadSaveRejectsMalformedVersion3Unit(t *testing.T) {
+	tests := []struct {
+		name  string
+		units string
+	}{
+		{"unit isn't an object", `[5]`},
+		{"rpg isn't an object", `[{"id": 0, "pos": [0, 1], "items": []}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			path := filepath.Join(t.TempDir(), "slot1.json")
+			v3 := `{"version": 3, "level": "Level_0", "rng": 5, "units": ` + tt.units + `}`
+			assert.NoError(t, os.WriteFile(path, []byte(v3), 0o644))
+
+			// When
+			_, err := ReadSave(path)
+
+			// Then
+			assert.ErrorContains(t, err, tt.name)
+		})
+	}
+}
+
+func TestSaveKeepsUnitMidAction(t *testing.T) {
+	// Given a unit that moved but hasn't acted
+	project := loadTestProject(t)
+	p := testUnit(0, PLAYER, PosXY{0, 1}, 5)
+	b := testLevelBattle(t, project, p)
+	idle := b.SaveData(1)
+	to := b.Reachable(p)[1]
+	_, err := b.Apply(Command{Type: MOVE, UnitID: p.id, To: to}, nil)
+	assert.NoError(t, err)
+
+	// When
+	data := b.SaveData(1)
+	loaded, err := data.Battle(project)
+
+	// Then it still has to act and can take its move back
+	assert.NoError(t, err)
+	assert.NotEqual(t, idle.Hash(), data.Hash())
+	assert.Equal(t, loaded.Units[p.id], loaded.MidAction())
+	_, err = loaded.Apply(Command{Type: UNMOVE, UnitID: p.id}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, PosXY{0, 1}, loaded.Units[p.id].Pos())
+	assert.Equal(t, idle, loaded.SaveData(1))
+}
+
+func TestReadSaveMigratesVersion4Moved(t *testing.T) {
+	// Given a save from before units could be saved mid action
+	path := filepath.Join(t.TempDir(), "slot1.json")
+	v4 := `{"version": 4, "level": "Level_0", "rng": 5, "units": [{"id": 0, "pos": [0, 1], "items": []}]}`
+	assert.NoError(t, os.WriteFile(path, []byte(v4), 0o644))
+
+	// When
+	data, err := ReadSave(path)
+
+	// Then
+	assert.NoError(t, err)
+	assert.False(t, data.Units[0].Moved)
+	assert.Equal(t, PosXY{0, 1}, data.Units[0].From)
+}
@@ -0,0 +1,142 @@
+package battle
+
+import (
+	"fmt"
+
+	"github.com/solarlune/ldtkgo"
+
+	"openFE/internal/ai"
+)
+
+// UnitEntity is the ldtk entity placed on the Entities layer for every unit a level starts with
+const UnitEntity = "Unit"
+
+// Spawn is a unit entity read from ldtk. Its fields are
+//
+//	Faction   String         "Player", "Enemy" or "Other"
+//	Job       String         job id (Ex: "noble")
+//	Level     Int            stats are raised to this level with average growths
+//	Name      String         optional, a surviving player unit with this name takes the spawn instead
+//	Inventory Array<String>  up to MaxItems item ids, the first weapon is equipped
+//	Behavior  String         ai behavior of non player units (Ex: "guard"), "aggressive" if empty
+//	Boss      Bool
+type Spawn struct {
+	Pos      PosXY
+	Faction  Faction
+	Job      Job
+	Level    int
+	Name     string
+	Items    []ItemID
+	Behavior ai.Behavior
+	Boss     bool
+}
+
+// LevelSpawns reads every unit entity of the level's Entities layer, levels without one have no spawns
+func LevelSpawns(level *ldtkgo.Level, width, height int) ([]Spawn, error) {
+	layer := level.LayerByIdentifier(EntitiesLayer)
+	if layer == nil {
+		return []Spawn{}, nil
+	}
+
+	spawns := []Spawn{}
+	for i, entity := range layer.Entities {
+		if entity.Identifier != UnitEntity {
+			continue
+		}
+		where := fmt.Sprintf("level %q entity %d", level.Identifier, i)
+		spawn, err := readSpawn(entity, layer, where)
+		if err != nil {
+			return nil, err
+		}
+		if spawn.Pos[X] < 0 || spawn.Pos[Y] < 0 || spawn.Pos[X] >= width || spawn.Pos[Y] >= height {
+			return nil, fmt.Errorf("%s: position %v is off the %dx%d map", where, spawn.Pos, width, height)
+		}
+		spawns = append(spawns, spawn)
+	}
+	return spawns, nil
+}
+
+func readSpawn(entity *ldtkgo.Entity, layer *ldtkgo.Layer, where string) (Spawn, error) {
+	x, y := layer.ToGridPosition(entity.Position[X], entity.Position[Y])
+	spawn := Spawn{Pos: PosXY{x, y}, Level: 1, Items: []ItemID{}}
+
+	property := func(field string) *ldtkgo.Property {
+		p := entity.PropertyByIdentifier(field)
+		if p == nil || p.IsNull() {
+			return nil
+		}
+		return p
+	}
+
+	p := property("Faction")
+	if p == nil {
+		return spawn, &FieldError{where, "Faction", "is required"}
+	}
+	faction, err := ParseFaction(p.AsString())
+	if err != nil {
+		return spawn, &FieldError{where, "Faction", err.Error()}
+	}
+	spawn.Faction = faction
+
+	p = property("Job")
+	if p == nil {
+		return spawn, &FieldError{where, "Job", "is required"}
+	}
+	spawn.Job = Job(p.AsString())
+	if _, ok := Jobs[spawn.Job]; !ok {
+		return spawn, &FieldError{where, "Job", fmt.Sprintf("unknown job %q", spawn.Job)}
+	}
+
+	if p = property("Level"); p != nil {
+		spawn.Level = p.AsInt()
+		if spawn.Level < 1 || spawn.Level > MaxLevel {
+			return spawn, &FieldError{where, "Level", fmt.Sprintf("must be between 1 and %d", MaxLevel)}
+		}
+	}
+
+	if p = property("Name"); p != nil {
+		spawn.Name = p.AsString()
+	}
+
+	if p = property("Inventory"); p != nil {
+		if len(p.AsArray()) > MaxItems {
+			return spawn, &FieldError{where, "Inventory", fmt.Sprintf("holds at most %d items", MaxItems)}
+		}
+		for i, v := range p.AsArray() {
+			id, _ := v.(string)
+			if _, ok := Items[ItemID(id)]; !ok {
+				return spawn, &FieldError{where, fmt.Sprintf("Inventory[%d]", i), fmt.Sprintf("unknown item %q", id)}
+			}
+			spawn.Items = append(spawn.Items, ItemID(id))
+		}
+	}
+
+	if p = property("Behavior"); p != nil && p.AsString() != "" {
+		behavior, err := ai.ParseBehavior(p.AsString())
+		if err != nil {
+			return spawn, &FieldError{where, "Behavior", err.Error()}
+		}
+		spawn.Behavior = behavior
+	}
+
+	if p = property("Boss"); p != nil {
+		spawn.Boss = p.AsBool()
+	}
+	return spawn, nil
+}
+
+// Unit builds the unit the spawn describes, guarding its spawn tile
+func (s Spawn) Unit(id int) *Unit {
+	rpg := NewRPG(s.Job, Stats{})
+	rpg.AutoLevel(s.Level)
+
+	u := NewUnit(id, rpg, s.Faction, s.Pos)
+	u.name = s.Name
+	u.boss = s.Boss
+	for _, item := range s.Items {
+		u.items = append(u.items, NewItem(item))
+	}
+	u.equipWeapon()
+	u.SetBehavior(s.Behavior, s.Pos)
+	return u
+}
@@ -0,0 +1,122 @@
+package battle
+
+import (
+	"testing"
+
+	"github.com/solarlune/ldtkgo"
+	"github.com/stretchr/testify/assert"
+
+	"openFE/internal/ai"
+)
+
+// Unit entity at cell pos of a 16px grid, fields are ldtk field identifier -> value
+func unitEntity(pos PosXY, fields map[string]any) *ldtkgo.Entity {
+	entity := &ldtkgo.Entity{Identifier: UnitEntity, Position: []int{pos[X] * 16, pos[Y] * 16}}
+	for id, v := range fields {
+		entity.Properties = append(entity.Properties, &ldtkgo.Property{Identifier: id, Value: v})
+	}
+	return entity
+}
+
+// Adds an Entities layer holding entities to the level
+func withEntities(level *ldtkgo.Level, entities ...*ldtkgo.Entity) {
+	layer := &ldtkgo.Layer{Identifier: EntitiesLayer, Type: ldtkgo.LayerTypeEntity, GridSize: 16, Entities: entities}
+	level.Layers = append(level.Layers, layer)
+}
+
+func TestNewLevelSpawnsUnits(t *testing.T) {
+	// Given
+	project := testProject(map[string][]string{"a": {"000", "000"}}, "a")
+	withEntities(project.Levels[0],
+		unitEntity(PosXY{0, 1}, map[string]any{"Faction": "Player", "Job": "test", "Level": 1.0, "Name": "Ike", "Inventory": []any{}}),
+		unitEntity(PosXY{2, 0}, map[string]any{"Faction": "enemy", "Job": "test", "Level": 5.0, "Inventory": []any{"potion", "iron_lance"}, "Behavior": "guard", "Boss": true}),
+	)
+
+	// When
+	b, err := NewLevel(project, "a", []*Unit{})
+
+	// Then
+	assert.NoError(t, err)
+	assert.Len(t, b.Units, 2)
+	p, boss := b.Units[0], b.Units[1]
+	assert.Equal(t, "Ike", p.Name())
+	assert.Equal(t, p.id, b.UnitAt(PosXY{0, 1}))
+	assert.Equal(t, ENEMY, boss.Faction())
+	assert.Equal(t, "Test", boss.Name())
+	assert.True(t, boss.Boss())
+	assert.Equal(t, ai.GUARD, boss.behavior)
+	assert.Equal(t, PosXY{2, 0}, boss.guardPos)
+	assert.Equal(t, []Item{{"potion", 3}, {"iron_lance", 45}}, boss.Items())
+	assert.Equal(t, "Iron Lance", boss.rpg.Weapon.Name)
+	assert.Equal(t, 5, boss.rpg.Level)
+	assert.Equal(t, boss.rpg.Stats.HP, boss.rpg.HP)
+}
+
+func TestNewLevelNamedSpawnTakesSurvivor(t *testing.T) {
+	// Given a survivor called Ike and a level with a spot for Ike
+	project := testProject(map[string][]string{"a": {"000", "000"}}, "a")
+	withEntities(project.Levels[0],
+		unitEntity(PosXY{2, 1}, map[string]any{"Faction": "Player", "Job": "test", "Name": "Ike"}),
+	)
+	other := testUnit(0, PLAYER, PosXY{2, 1}, 2)
+	ike := testUnit(1, PLAYER, PosXY{0, 0}, 2)
+	ike.name = "Ike"
+	ike.rpg.Level = 7
+
+	// When
+	b, err := NewLevel(project, "a", []*Unit{other, ike})
+
+	// Then Ike keeps his stats on the spawn and the other survivor makes room
+	assert.NoError(t, err)
+	assert.Len(t, b.Units, 2)
+	assert.Equal(t, ike.id, b.UnitAt(PosXY{2, 1}))
+	assert.Equal(t, 7, b.Units[ike.id].rpg.Level)
+	assert.Equal(t, PosXY{2, 0}, b.Units[other.id].Pos())
+}
+
+func TestNewLevelSpawnErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		pos    PosXY
+		fields map[string]any
+		err    string
+	}{
+		{"unknown job", PosXY{0, 0}, map[string]any{"Faction": "Enemy", "Job": "nope"}, `unknown job "nope"`},
+		{"unknown item", PosXY{0, 0}, map[string]any{"Faction": "Enemy", "Job": "test", "Inventory": []any{"nope"}}, `"Inventory[0]": unknown item "nope"`},
+		{"too many items", PosXY{0, 0}, map[string]any{"Faction": "Enemy", "Job": "test", "Inventory": []any{"potion", "potion", "potion", "potion", "potion", "potion"}}, `"Inventory": holds at most 5 items`},
+		{"unknown faction", PosXY{0, 0}, map[string]any{"Faction": "Pirates", "Job": "test"}, `unknown faction "Pirates"`},
+		{"missing faction", PosXY{0, 0}, map[string]any{"Job": "test"}, `"Faction": is required`},
+		{"unknown behavior", PosXY{0, 0}, map[string]any{"Faction": "Enemy", "Job": "test", "Behavior": "berserk"}, `unknown behavior "berserk"`},
+		{"out of bounds", PosXY{5, 0}, map[string]any{"Faction": "Enemy", "Job": "test"}, "is off the 3x2 map"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			project := testProject(map[string][]string{"a": {"000", "000"}}, "a")
+			withEntities(project.Levels[0], unitEntity(tt.pos, tt.fields))
+
+			// When
+			_, err := NewLevel(project, "a", []*Unit{})
+
+			// Then
+			assert.ErrorContains(t, err, `level "a" entity 0`)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestBossLevelClearedByBossAlone(t *testing.T) {
+	// Given
+	p := testUnit(0, PLAYER, PosXY{0, 0}, 2)
+	e := testUnit(1, ENEMY, PosXY{1, 0}, 2)
+	boss := testUnit(2, ENEMY, PosXY{2, 0}, 2)
+	boss.boss = true
+	b := testBattle([]string{"..."}, []*Unit{p, e, boss})
+
+	// When
+	b.KillUnit(boss)
+
+	// Then
+	assert.False(t, e.IsDead())
+	assert.True(t, b.Cleared())
+}
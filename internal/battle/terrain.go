@@ -0,0 +1,94 @@
+package battle
+
+import "openFE/internal/combat"
+
+// Terrain is the ldtk intgrid value of a cell, 0 is an empty cell
+type Terrain int
+
+const (
+	PLAINS Terrain = iota
+	WALL
+	FOREST
+	MOUNTAIN
+	WATER
+	FORT
+	THRONE
+)
+
+const impassable = -1
+
+type TerrainData struct {
+	Name     string
+	MoveCost map[MovementType]int // impassable if it can't be entered
+	Defense  int
+	Avoid    int
+	Heal     int // Percent of max HP restored at the start of the unit's phase
+}
+
+var terrainData = map[Terrain]TerrainData{
+	PLAINS: {
+		Name:     "Plains",
+		MoveCost: map[MovementType]int{INFANTRY: 1, ARMORED: 1, CAVALRY: 1, FLIER: 1},
+	},
+	WALL: {
+		Name:     "Wall",
+		MoveCost: map[MovementType]int{INFANTRY: impassable, ARMORED: impassable, CAVALRY: impassable, FLIER: impassable},
+	},
+	FOREST: {
+		Name:     "Forest",
+		MoveCost: map[MovementType]int{INFANTRY: 2, ARMORED: 2, CAVALRY: 3, FLIER: 1},
+		Defense:  1,
+		Avoid:    20,
+	},
+	MOUNTAIN: {
+		Name:     "Mountain",
+		MoveCost: map[MovementType]int{INFANTRY: 3, ARMORED: impassable, CAVALRY: impassable, FLIER: 1},
+		Defense:  2,
+		Avoid:    30,
+	},
+	WATER: {
+		Name:     "Water",
+		MoveCost: map[MovementType]int{INFANTRY: impassable, ARMORED: impassable, CAVALRY: impassable, FLIER: 1},
+	},
+	FORT: {
+		Name:     "Fort",
+		MoveCost: map[MovementType]int{INFANTRY: 2, ARMORED: 2, CAVALRY: 2, FLIER: 1},
+		Defense:  2,
+		Avoid:    20,
+		Heal:     20,
+	},
+	THRONE: {
+		Name:     "Throne",
+		MoveCost: map[MovementType]int{INFANTRY: 1, ARMORED: 1, CAVALRY: 1, FLIER: 1},
+		Defense:  3,
+		Avoid:    30,
+		Heal:     10,
+	},
+}
+
+// Unknown intgrid values are treated as plains so a new value painted in ldtk doesn't break the map
+func (t Terrain) Data() TerrainData {
+	data, ok := terrainData[t]
+	if !ok {
+		return terrainData[PLAINS]
+	}
+	return data
+}
+
+func (t Terrain) MoveCost(mt MovementType) int {
+	cost, ok := t.Data().MoveCost[mt]
+	if !ok {
+		return impassable
+	}
+	return cost
+}
+
+func (t Terrain) Bonus() combat.TerrainBonus {
+	data := t.Data()
+	return combat.TerrainBonus{Def: data.Defense, Avoid: data.Avoid}
+}
+
+// HP a unit with maxHP gets back for starting its phase on t
+func (t Terrain) HealAmount(maxHP int) int {
+	return maxHP * t.Data().Heal / 100
+}
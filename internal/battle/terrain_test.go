@@ -0,0 +1,52 @@
+package battle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"openFE/internal/combat"
+)
+
+func TestMoveCost(t *testing.T) {
+	tests := []struct {
+		terrain      Terrain
+		movementType MovementType
+		expected     int
+	}{
+		{PLAINS, INFANTRY, 1},
+		{FOREST, INFANTRY, 2},
+		{FOREST, CAVALRY, 3},
+		{FOREST, FLIER, 1},
+		{MOUNTAIN, INFANTRY, 3},
+		{MOUNTAIN, ARMORED, impassable},
+		{WATER, CAVALRY, impassable},
+		{WATER, FLIER, 1},
+		{WALL, FLIER, impassable},
+		{Terrain(99), INFANTRY, 1},
+		{PLAINS, MovementType("boat"), impassable},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, tt.terrain.MoveCost(tt.movementType), "%s %s", tt.terrain.Data().Name, tt.movementType)
+	}
+}
+
+func TestTerrainBonusInCombat(t *testing.T) {
+	// Given the same defender on plains and in a forest
+	a := testUnit(0, PLAYER, PosXY{1, 0}, 2)
+	a.rpg.Weapon = combat.Weapon{Might: 5, Hit: 70, MinRange: 1, MaxRange: 1}
+	onPlains := testUnit(1, ENEMY, PosXY{0, 0}, 2)
+	inForest := testUnit(2, ENEMY, PosXY{2, 0}, 2)
+	b := testBattle([]string{"..f"}, []*Unit{a, onPlains, inForest})
+
+	// When
+	plains := b.Combatant(onPlains)
+	forest := b.Combatant(inForest)
+
+	// Then
+	assert.Equal(t, combat.TerrainBonus{}, plains.Terrain)
+	assert.Equal(t, combat.TerrainBonus{Def: 1, Avoid: 20}, forest.Terrain)
+	assert.Equal(t, combat.Damage(b.Combatant(a), plains)-1, combat.Damage(b.Combatant(a), forest))
+	assert.Equal(t, combat.HitRate(b.Combatant(a), plains)-20, combat.HitRate(b.Combatant(a), forest))
+}
@@ -0,0 +1,82 @@
+package battle
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+
+	"openFE/internal/combat"
+)
+
+const TriangleFile = "assets/demo/triangle.json"
+
+// Triangle is the weapon triangle every exchange uses, empty until it's loaded
+var Triangle = combat.TriangleMatrix{}
+
+// LoadTriangle reads file into Triangle, which is left alone if the file is invalid
+func LoadTriangle(file string) error {
+	matrix, err := ReadTriangle(file)
+	if err != nil {
+		return err
+	}
+	Triangle = matrix
+	return nil
+}
+
+// ReadTriangle reads a weapon triangle matrix. The file maps attacker type ->
+// defender type -> {"hit", "damage"}.
+func ReadTriangle(file string) (combat.TriangleMatrix, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	matrix := combat.TriangleMatrix{}
+	decoder := json.NewDecoder(f)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&matrix); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	if err := validateTriangle(file, matrix); err != nil {
+		return nil, err
+	}
+	return matrix, nil
+}
+
+func validateTriangle(file string, matrix combat.TriangleMatrix) error {
+	attackers := []string{}
+	for attacker := range matrix {
+		attackers = append(attackers, string(attacker))
+	}
+	sort.Strings(attackers)
+
+	for _, attacker := range attackers {
+		if !slices.Contains(combat.WeaponTypes, combat.WeaponType(attacker)) {
+			return &FieldError{file, attacker, "unknown weapon type"}
+		}
+		defenders := []string{}
+		for defender := range matrix[combat.WeaponType(attacker)] {
+			defenders = append(defenders, string(defender))
+		}
+		sort.Strings(defenders)
+		for _, defender := range defenders {
+			if !slices.Contains(combat.WeaponTypes, combat.WeaponType(defender)) {
+				return &FieldError{file, attacker + "." + defender, "unknown weapon type"}
+			}
+		}
+	}
+	return nil
+}
+
+// Forecast is what attacker and defender can expect if attacker attacks from where it stands
+func (b *Battle) Forecast(attacker, defender *Unit) combat.Forecast {
+	return combat.NewForecast(b.Facing(attacker, defender))
+}
+
+// Facing is the combatants of attacker and defender with the triangle set against each other
+func (b *Battle) Facing(attacker, defender *Unit) (combat.Combatant, combat.Combatant) {
+	return combat.Face(b.Combatant(attacker), b.Combatant(defender), Triangle)
+}
@@ -0,0 +1,87 @@
+package battle
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"openFE/internal/combat"
+	"openFE/internal/rng"
+)
+
+func TestLoadDemoTriangle(t *testing.T) {
+	// When
+	matrix, err := ReadTriangle(filepath.Join("..", "..", TriangleFile))
+
+	// Then every physical and magic pair is opposite
+	assert.NoError(t, err)
+	for _, pair := range [][2]combat.WeaponType{{combat.SWORD, combat.AXE}, {combat.AXE, combat.LANCE}, {combat.LANCE, combat.SWORD}, {combat.ANIMA, combat.LIGHT}, {combat.LIGHT, combat.DARK}, {combat.DARK, combat.ANIMA}} {
+		win := matrix[pair[0]][pair[1]]
+		assert.Equal(t, combat.Modifier{Hit: 15, Damage: 1}, win, "%s vs %s", pair[0], pair[1])
+		assert.Equal(t, combat.Modifier{Hit: -win.Hit, Damage: -win.Damage}, matrix[pair[1]][pair[0]])
+	}
+}
+
+func TestLoadTriangleErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		field   string
+	}{
+		{"unknown attacker", `{"spoon": {"sword": {"hit": 5}}}`, "spoon"},
+		{"unknown defender", `{"sword": {"axe": {"hit": 5}, "spoon": {"hit": 5}}}`, "sword.spoon"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			dir := writeItemDir(t, map[string]string{"triangle.json": tt.content})
+
+			// When
+			matrix, err := ReadTriangle(filepath.Join(dir, "triangle.json"))
+
+			// Then
+			var fieldErr *FieldError
+			if assert.ErrorAs(t, err, &fieldErr) {
+				assert.Equal(t, tt.field, fieldErr.Field)
+			}
+			assert.Nil(t, matrix)
+		})
+	}
+
+	// When a modifier has a typo
+	dir := writeItemDir(t, map[string]string{"triangle.json": `{"sword": {"axe": {"hti": 5}}}`})
+
+	// Then
+	_, err := ReadTriangle(filepath.Join(dir, "triangle.json"))
+	assert.ErrorContains(t, err, "hti")
+}
+
+func TestForecastMatchesExchange(t *testing.T) {
+	keepRegistries(t)
+	// Given a lance against a sword in a forest
+	Triangle = combat.TriangleMatrix{
+		combat.LANCE: {combat.SWORD: {Hit: 15, Damage: 1}},
+		combat.SWORD: {combat.LANCE: {Hit: -15, Damage: -1}},
+	}
+	p := testUnit(0, PLAYER, PosXY{0, 0}, 2)
+	p.rpg.Weapon = combat.Weapon{Type: combat.LANCE, Might: 7, Hit: 80, MinRange: 1, MaxRange: 1}
+	e := testUnit(1, ENEMY, PosXY{1, 0}, 2)
+	e.rpg.Weapon = combat.Weapon{Type: combat.SWORD, Might: 5, Hit: 90, MinRange: 1, MaxRange: 1}
+	b := testBattle([]string{".f"}, []*Unit{p, e})
+
+	// When
+	sut := b.Forecast(p, e)
+
+	// Then
+	assert.Equal(t, 1, sut.Attacker.Advantage)
+	assert.Equal(t, -1, sut.Defender.Advantage)
+	assert.Equal(t, 6+7+1-4-1, sut.Attacker.Damage) // Str + might + triangle - def - forest
+	result := b.Attack(p, e, rng.New(3))
+	for _, s := range result.Strikes {
+		if s.Hit && !s.Crit && s.AttackerID == p.id {
+			assert.Equal(t, sut.Attacker.Damage, s.Damage)
+		}
+	}
+}
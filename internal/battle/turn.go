@@ -0,0 +1,102 @@
+package battle
+
+// Phases go Player -> Enemy -> Other, the turn counter goes up when it's the player's phase again
+var phaseOrder = []Faction{PLAYER, ENEMY, OTHER}
+
+func (f Faction) String() string {
+	switch f {
+	case PLAYER:
+		return "Player"
+	case ENEMY:
+		return "Enemy"
+	case OTHER:
+		return "Other"
+	}
+	return "Unknown"
+}
+
+func (b *Battle) Turn() int {
+	return b.turn
+}
+
+func (b *Battle) Phase() Faction {
+	return b.phase
+}
+
+// A unit can move and act once per phase of its own faction
+func (b *Battle) CanAct(u *Unit) bool {
+	return !u.dead && !u.waited && u.faction == b.phase
+}
+
+// Wait marks u as done for this phase, the phase ends once every unit in it has waited.
+// Returns the heals of the next phase if it started.
+func (b *Battle) Wait(u *Unit) []Event {
+	u.waited = true
+	if b.phaseDone() {
+		return b.EndPhase()
+	}
+	return nil
+}
+
+func (b *Battle) phaseDone() bool {
+	for _, u := range b.Units {
+		if b.CanAct(u) {
+			return false
+		}
+	}
+	return true
+}
+
+// EndPhase moves on to the next faction that still has units, skipping empty ones.
+// Its units standing on healing terrain recover HP, one HEALED event each.
+func (b *Battle) EndPhase() []Event {
+	for _, u := range b.Units {
+		u.waited = false
+		u.moved = false
+	}
+	b.changes += 1
+
+	i := 0
+	for j, f := range phaseOrder {
+		if f == b.phase {
+			i = j
+		}
+	}
+	for range phaseOrder {
+		i = (i + 1) % len(phaseOrder)
+		if phaseOrder[i] == PLAYER {
+			b.turn += 1
+		}
+		if b.hasUnits(phaseOrder[i]) {
+			break
+		}
+	}
+	b.phase = phaseOrder[i]
+	return b.healPhase()
+}
+
+func (b *Battle) healPhase() []Event {
+	events := []Event{}
+	for _, u := range b.Units {
+		if u.dead || u.faction != b.phase {
+			continue
+		}
+		maxHP := u.rpg.Stats.HP
+		heal := min(b.TerrainAt(u.posXY).HealAmount(maxHP), maxHP-u.rpg.HP)
+		if heal <= 0 {
+			continue
+		}
+		u.rpg.HP += heal
+		events = append(events, Event{Type: HEALED, UnitID: u.id, Amount: heal})
+	}
+	return events
+}
+
+func (b *Battle) hasUnits(f Faction) bool {
+	for _, u := range b.Units {
+		if !u.dead && u.faction == f {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package battle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitEndsPhaseOnceEveryoneActed(t *testing.T) {
+	// Given
+	a := testUnit(0, PLAYER, PosXY{0, 0}, 2)
+	other := testUnit(1, PLAYER, PosXY{1, 0}, 2)
+	enemy := testUnit(2, ENEMY, PosXY{3, 0}, 2)
+	b := testBattle([]string{"...."}, []*Unit{a, other, enemy})
+
+	// When
+	b.Wait(a)
+
+	// Then
+	assert.False(t, b.CanAct(a))
+	assert.True(t, b.CanAct(other))
+	assert.Equal(t, PLAYER, b.Phase())
+
+	// When
+	b.Wait(other)
+
+	// Then
+	assert.Equal(t, ENEMY, b.Phase())
+	assert.Equal(t, 1, b.Turn())
+	assert.False(t, a.Waited())
+	assert.True(t, b.CanAct(enemy))
+	assert.False(t, b.CanAct(a))
+}
+
+func TestEndPhaseSkipsEmptyFactionsAndCountsTurns(t *testing.T) {
+	// Given
+	a := testUnit(0, PLAYER, PosXY{0, 0}, 2)
+	enemy := testUnit(1, ENEMY, PosXY{3, 0}, 2)
+	b := testBattle([]string{"...."}, []*Unit{a, enemy})
+
+	// When
+	b.EndPhase()
+	b.EndPhase()
+
+	// Then
+	assert.Equal(t, PLAYER, b.Phase())
+	assert.Equal(t, 2, b.Turn())
+}
+
+func TestDeadUnitsDontHoldUpPhase(t *testing.T) {
+	// Given
+	a := testUnit(0, PLAYER, PosXY{0, 0}, 2)
+	other := testUnit(1, PLAYER, PosXY{1, 0}, 2)
+	enemy := testUnit(2, ENEMY, PosXY{3, 0}, 2)
+	b := testBattle([]string{"...."}, []*Unit{a, other, enemy})
+	b.KillUnit(other)
+
+	// When
+	b.Wait(a)
+
+	// Then
+	assert.Equal(t, ENEMY, b.Phase())
+}
+
+func TestPhaseStartHealsOnForts(t *testing.T) {
+	// Given a hurt player in a fort and one on plains
+	fort := testUnit(0, PLAYER, PosXY{0, 0}, 2)
+	fort.rpg.HP = 10
+	plains := testUnit(1, PLAYER, PosXY{1, 0}, 2)
+	plains.rpg.HP = 10
+	nearlyFull := testUnit(2, PLAYER, PosXY{2, 0}, 2)
+	nearlyFull.rpg.HP = 19
+	enemy := testUnit(3, ENEMY, PosXY{3, 0}, 2)
+	b := testBattle([]string{"F.T."}, []*Unit{fort, plains, nearlyFull, enemy})
+
+	// When the enemy phase starts
+	events := b.EndPhase()
+
+	// Then only the enemy's units could have healed
+	assert.Empty(t, events)
+	assert.Equal(t, 10, fort.rpg.HP)
+
+	// When the player phase starts
+	events = b.EndPhase()
+
+	// Then
+	assert.Equal(t, []Event{
+		{Type: HEALED, UnitID: fort.id, Amount: 4},
+		{Type: HEALED, UnitID: nearlyFull.id, Amount: 1}, // Capped at max HP
+	}, events)
+	assert.Equal(t, 14, fort.rpg.HP)
+	assert.Equal(t, 10, plains.rpg.HP)
+	assert.Equal(t, 20, nearlyFull.rpg.HP)
+}
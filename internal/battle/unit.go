@@ -0,0 +1,93 @@
+package battle
+
+import (
+	"slices"
+
+	"openFE/internal/ai"
+)
+
+type Unit struct {
+	id           int
+	name         string
+	posXYHistory []PosXY
+	posXY        PosXY
+	rpg          RPG
+	faction      Faction
+	waited       bool // Already moved and acted this phase
+	moved        bool // Already moved this phase, it still has to attack or wait
+	dead         bool
+	behavior     ai.Behavior // Only used when the unit's faction isn't controlled by the player
+	guardPos     PosXY       // Tile ai.GUARD units stay around
+	boss         bool        // Levels with a boss are cleared once every boss is dead
+	items        []Item      // At most MaxItems, the first weapon is the equipped one
+}
+
+func NewUnit(id int, rpg RPG, faction Faction, posXY PosXY) *Unit {
+	return &Unit{
+		id:           id,
+		posXYHistory: []PosXY{posXY},
+		posXY:        posXY,
+		rpg:          rpg,
+		faction:      faction,
+	}
+}
+
+func (u *Unit) ID() int {
+	return u.id
+}
+
+// Name is what the unit is called, its job's name if it was given none
+func (u *Unit) Name() string {
+	if u.name == "" {
+		return u.rpg.Job.Data().Name
+	}
+	return u.name
+}
+
+func (u *Unit) Boss() bool {
+	return u.boss
+}
+
+func (u *Unit) Items() []Item {
+	return slices.Clone(u.items)
+}
+
+func (u *Unit) Pos() PosXY {
+	return u.posXY
+}
+
+func (u *Unit) RPG() RPG {
+	return u.rpg
+}
+
+func (u *Unit) Waited() bool {
+	return u.waited
+}
+
+func (u *Unit) Moved() bool {
+	return u.moved
+}
+
+func (u *Unit) Faction() Faction {
+	return u.faction
+}
+
+func (u *Unit) posXYAppendHistory(posXY PosXY) {
+	u.posXYHistory = append(u.posXYHistory, posXY)
+}
+
+// Drops the newest position and returns the one the unit stood on before it
+func (u *Unit) posXYPopHistory() PosXY {
+	if len(u.posXYHistory) > 1 {
+		u.posXYHistory = u.posXYHistory[:len(u.posXYHistory)-1]
+	}
+	return u.posXYHistory[len(u.posXYHistory)-1]
+}
+
+// Where the unit stood before its move, its position if it hasn't moved
+func (u *Unit) from() PosXY {
+	if !u.moved || len(u.posXYHistory) < 2 {
+		return u.posXY
+	}
+	return u.posXYHistory[len(u.posXYHistory)-2]
+}
@@ -0,0 +1,220 @@
+// Package combat resolves attack/counter-attack exchanges between two units.
+// It knows nothing about ebiten or the grid, core converts units into
+// Combatants, calls Resolve and applies the Result back to the map.
+package combat
+
+const (
+	DoubleAttackSpeed = 4 // Spd difference needed to strike twice
+	CritMultiplier    = 3
+)
+
+type Weapon struct {
+	Name     string     `json:"name"`
+	Type     WeaponType `json:"type"` // Side of the weapon triangle, "" is neutral to everything
+	Might    int        `json:"might"`
+	Hit      int        `json:"hit"`
+	Crit     int        `json:"crit"`
+	MinRange int        `json:"minRange"`
+	MaxRange int        `json:"maxRange"`
+}
+
+// Magic weapons hit with Mag against Res instead of Str against Def
+func (w Weapon) IsMagic() bool {
+	return w.Type == ANIMA || w.Type == LIGHT || w.Type == DARK
+}
+
+func (w Weapon) InRange(distance int) bool {
+	return distance >= w.MinRange && distance <= w.MaxRange
+}
+
+// TerrainBonus is what the tile a combatant stands on adds to its defense and avoid
+type TerrainBonus struct {
+	Def   int
+	Avoid int
+}
+
+type Combatant struct {
+	ID       int
+	Pos      [2]int
+	HP       int
+	Str      int
+	Mag      int
+	Skl      int
+	Spd      int
+	Lck      int
+	Def      int
+	Res      int
+	Weapon   Weapon
+	Terrain  TerrainBonus
+	Triangle Modifier // Weapon triangle against the opponent, see Face
+}
+
+// Roller is where hit and crit rolls come from, *rng.RNG satisfies it.
+// Tests use a scripted roller so exchanges are fully deterministic.
+type Roller interface {
+	Intn(n int) int
+}
+
+func Distance(a, b [2]int) int {
+	return abs(a[0]-b[0]) + abs(a[1]-b[1])
+}
+
+func CanAttack(attacker, defender Combatant) bool {
+	return attacker.HP > 0 && attacker.Weapon.InRange(Distance(attacker.Pos, defender.Pos))
+}
+
+// Targets returns the candidates that attacker can hit from where it stands
+func Targets(attacker Combatant, candidates []Combatant) []Combatant {
+	targets := []Combatant{}
+	for _, c := range candidates {
+		if c.ID == attacker.ID || c.HP <= 0 {
+			continue
+		}
+		if CanAttack(attacker, c) {
+			targets = append(targets, c)
+		}
+	}
+	return targets
+}
+
+func Damage(attacker, defender Combatant) int {
+	might := attacker.Weapon.Might + attacker.Triangle.Damage
+	power, defense := attacker.Str, defender.Def
+	if attacker.Weapon.IsMagic() {
+		power, defense = attacker.Mag, defender.Res
+	}
+	return max(0, power+might-defense-defender.Terrain.Def)
+}
+
+func HitRate(attacker, defender Combatant) int {
+	hit := attacker.Weapon.Hit + attacker.Triangle.Hit + attacker.Skl*2 + attacker.Lck/2
+	avoid := defender.Spd*2 + defender.Lck + defender.Terrain.Avoid
+	return clamp(hit-avoid, 0, 100)
+}
+
+func CritRate(attacker, defender Combatant) int {
+	crit := attacker.Weapon.Crit + attacker.Skl/2
+	return clamp(crit-defender.Lck, 0, 100)
+}
+
+func Doubles(attacker, defender Combatant) bool {
+	return attacker.Spd-defender.Spd >= DoubleAttackSpeed
+}
+
+// Side is what one combatant can expect from an exchange
+type Side struct {
+	HP        int
+	Damage    int
+	Hit       int
+	Crit      int
+	Strikes   int // 0 when it can't reach the other, 2 when it doubles
+	Advantage int // Weapon triangle, see Modifier.Advantage
+}
+
+type Forecast struct {
+	Attacker Side
+	Defender Side
+}
+
+// NewForecast is what Resolve would roll with, before any roll is made. Both
+// sides need their triangle set, see Face.
+func NewForecast(attacker, defender Combatant) Forecast {
+	return Forecast{Attacker: side(attacker, defender), Defender: side(defender, attacker)}
+}
+
+func side(c, other Combatant) Side {
+	s := Side{
+		HP:        c.HP,
+		Damage:    Damage(c, other),
+		Hit:       HitRate(c, other),
+		Crit:      CritRate(c, other),
+		Advantage: c.Triangle.Advantage(),
+	}
+	if CanAttack(c, other) {
+		s.Strikes = 1
+		if Doubles(c, other) {
+			s.Strikes = 2
+		}
+	}
+	return s
+}
+
+type Strike struct {
+	AttackerID int
+	DefenderID int
+	Hit        bool
+	Crit       bool
+	Damage     int
+	DefenderHP int // HP left after this strike
+}
+
+type Result struct {
+	Strikes  []Strike
+	Attacker Combatant // HP is the value after the exchange
+	Defender Combatant
+}
+
+func (r Result) AttackerDied() bool {
+	return r.Attacker.HP <= 0
+}
+
+func (r Result) DefenderDied() bool {
+	return r.Defender.HP <= 0
+}
+
+// Resolve runs the exchange in Fire Emblem order: attacker, counter, then the
+// follow up of whoever doubles. The exchange stops as soon as someone dies.
+// The defender only counters if the attacker is inside its weapon range.
+func Resolve(attacker, defender Combatant, roller Roller) Result {
+	a := &attacker
+	d := &defender
+	order := []*Combatant{a, d}
+	if Doubles(attacker, defender) {
+		order = append(order, a)
+	} else if Doubles(defender, attacker) {
+		order = append(order, d)
+	}
+
+	strikes := []Strike{}
+	for _, striker := range order {
+		target := d
+		if striker == d {
+			target = a
+		}
+		if a.HP <= 0 || d.HP <= 0 {
+			break
+		}
+		if !CanAttack(*striker, *target) {
+			continue
+		}
+		strikes = append(strikes, strike(striker, target, roller))
+	}
+
+	return Result{Strikes: strikes, Attacker: *a, Defender: *d}
+}
+
+func strike(attacker, defender *Combatant, roller Roller) Strike {
+	s := Strike{AttackerID: attacker.ID, DefenderID: defender.ID}
+	s.Hit = roller.Intn(100) < HitRate(*attacker, *defender)
+	if s.Hit {
+		s.Damage = Damage(*attacker, *defender)
+		s.Crit = roller.Intn(100) < CritRate(*attacker, *defender)
+		if s.Crit {
+			s.Damage *= CritMultiplier
+		}
+		defender.HP = max(0, defender.HP-s.Damage)
+	}
+	s.DefenderHP = defender.HP
+	return s
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func clamp(x, lo, hi int) int {
+	return min(max(x, lo), hi)
+}
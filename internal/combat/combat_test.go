@@ -0,0 +1,168 @@
+package combat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Returns the scripted rolls in order
+type fixedRoller struct {
+	rolls []int
+}
+
+func (f *fixedRoller) Intn(n int) int {
+	r := f.rolls[0]
+	f.rolls = f.rolls[1:]
+	return r
+}
+
+var ironSword = Weapon{Name: "Iron Sword", Might: 5, Hit: 90, Crit: 0, MinRange: 1, MaxRange: 1}
+var ironBow = Weapon{Name: "Iron Bow", Might: 6, Hit: 85, Crit: 0, MinRange: 2, MaxRange: 2}
+
+func fighter(id int, pos [2]int) Combatant {
+	return Combatant{ID: id, Pos: pos, HP: 20, Str: 6, Skl: 5, Spd: 5, Lck: 2, Def: 3, Weapon: ironSword}
+}
+
+func TestFormulas(t *testing.T) {
+	// Given
+	a := fighter(0, [2]int{0, 0})
+	d := fighter(1, [2]int{0, 1})
+
+	// Then
+	assert.Equal(t, 8, Damage(a, d))   // 6 + 5 - 3
+	assert.Equal(t, 89, HitRate(a, d)) // (90 + 10 + 1) - (10 + 2)
+	assert.Equal(t, 0, CritRate(a, d)) // 0 + 2 - 2
+	assert.False(t, Doubles(a, d))
+}
+
+func TestMagicUsesMagAgainstRes(t *testing.T) {
+	// Given a mage against a unit with high Def and low Res
+	a := fighter(0, [2]int{0, 0})
+	a.Mag = 7
+	a.Weapon = Weapon{Name: "Fire", Type: ANIMA, Might: 5, Hit: 90, MinRange: 1, MaxRange: 2}
+	d := fighter(1, [2]int{0, 1})
+	d.Def = 10
+	d.Res = 1
+
+	// Then
+	assert.Equal(t, 11, Damage(a, d)) // 7 + 5 - 1
+	assert.Equal(t, 8, Damage(d, a))  // 6 + 5 - 3, the sword stays physical
+}
+
+func TestTerrainBonus(t *testing.T) {
+	// Given a defender in a fort
+	a := fighter(0, [2]int{0, 0})
+	d := fighter(1, [2]int{0, 1})
+	d.Terrain = TerrainBonus{Def: 2, Avoid: 20}
+
+	// Then
+	assert.Equal(t, 6, Damage(a, d))   // 6 + 5 - (3 + 2)
+	assert.Equal(t, 69, HitRate(a, d)) // (90 + 10 + 1) - (10 + 2 + 20)
+	assert.Equal(t, 8, Damage(d, a))   // The attacker's own tile doesn't matter
+}
+
+func TestTargetsInRange(t *testing.T) {
+	// Given
+	a := fighter(0, [2]int{2, 2})
+	adjacent := fighter(1, [2]int{2, 3})
+	far := fighter(2, [2]int{4, 4})
+	dead := fighter(3, [2]int{1, 2})
+	dead.HP = 0
+
+	// When
+	sut := Targets(a, []Combatant{a, adjacent, far, dead})
+
+	// Then
+	assert.Equal(t, []Combatant{adjacent}, sut)
+}
+
+func TestResolveExchange(t *testing.T) {
+	// Given
+	a := fighter(0, [2]int{0, 0})
+	d := fighter(1, [2]int{0, 1})
+	roller := &fixedRoller{rolls: []int{
+		10, 99, // attacker hits, no crit
+		95, // defender misses
+	}}
+
+	// When
+	sut := Resolve(a, d, roller)
+
+	// Then
+	assert.Equal(t, []Strike{
+		{AttackerID: 0, DefenderID: 1, Hit: true, Damage: 8, DefenderHP: 12},
+		{AttackerID: 1, DefenderID: 0, Hit: false, DefenderHP: 20},
+	}, sut.Strikes)
+	assert.Equal(t, 20, sut.Attacker.HP)
+	assert.Equal(t, 12, sut.Defender.HP)
+}
+
+func TestResolveDoubleAndKill(t *testing.T) {
+	// Given
+	a := fighter(0, [2]int{0, 0})
+	a.Spd = 10
+	d := fighter(1, [2]int{1, 0})
+	d.HP = 10
+	roller := &fixedRoller{rolls: []int{
+		0, 99, // attacker hits
+		0, 99, // defender hits back
+		0, 99, // attacker follow up kills
+	}}
+
+	// When
+	sut := Resolve(a, d, roller)
+
+	// Then
+	assert.Len(t, sut.Strikes, 3)
+	assert.True(t, sut.DefenderDied())
+	assert.False(t, sut.AttackerDied())
+	assert.Equal(t, 12, sut.Attacker.HP)
+}
+
+func TestResolveStopsWhenDefenderDies(t *testing.T) {
+	// Given
+	a := fighter(0, [2]int{0, 0})
+	d := fighter(1, [2]int{1, 0})
+	d.HP = 5
+	roller := &fixedRoller{rolls: []int{0, 99}}
+
+	// When
+	sut := Resolve(a, d, roller)
+
+	// Then
+	assert.Len(t, sut.Strikes, 1)
+	assert.True(t, sut.DefenderDied())
+}
+
+func TestResolveNoCounterOutOfRange(t *testing.T) {
+	// Given
+	archer := fighter(0, [2]int{0, 0})
+	archer.Weapon = ironBow
+	d := fighter(1, [2]int{2, 0})
+	roller := &fixedRoller{rolls: []int{0, 99}}
+
+	// When
+	sut := Resolve(archer, d, roller)
+
+	// Then
+	assert.Len(t, sut.Strikes, 1)
+	assert.Equal(t, 0, sut.Strikes[0].AttackerID)
+}
+
+func TestResolveCrit(t *testing.T) {
+	// Given
+	a := fighter(0, [2]int{0, 0})
+	a.Weapon.Crit = 50
+	d := fighter(1, [2]int{1, 0})
+	d.HP = 30
+	roller := &fixedRoller{rolls: []int{0, 0, 99}}
+
+	// When
+	sut := Resolve(a, d, roller)
+
+	// Then
+	assert.True(t, sut.Strikes[0].Crit)
+	assert.Equal(t, 24, sut.Strikes[0].Damage)
+	assert.Equal(t, 6, sut.Defender.HP)
+}
@@ -0,0 +1,48 @@
+package combat
+
+type WeaponType string
+
+const (
+	SWORD WeaponType = "sword"
+	LANCE WeaponType = "lance"
+	AXE   WeaponType = "axe"
+	BOW   WeaponType = "bow"
+	ANIMA WeaponType = "anima"
+	LIGHT WeaponType = "light"
+	DARK  WeaponType = "dark"
+)
+
+var WeaponTypes = []WeaponType{SWORD, LANCE, AXE, BOW, ANIMA, LIGHT, DARK}
+
+// Modifier is what the weapon triangle adds to the attacker's hit and damage
+type Modifier struct {
+	Hit    int `json:"hit"`
+	Damage int `json:"damage"`
+}
+
+// TriangleMatrix[attacker][defender] is the attacker's modifier, missing pairs are neutral
+type TriangleMatrix map[WeaponType]map[WeaponType]Modifier
+
+// Bonus is what attacker's weapon gets against defender's
+func (m TriangleMatrix) Bonus(attacker, defender Weapon) Modifier {
+	return m[attacker.Type][defender.Type]
+}
+
+// Face sets what the triangle gives a and d against each other, the formulas
+// read it from Combatant.Triangle
+func Face(a, d Combatant, m TriangleMatrix) (Combatant, Combatant) {
+	a.Triangle = m.Bonus(a.Weapon, d.Weapon)
+	d.Triangle = m.Bonus(d.Weapon, a.Weapon)
+	return a, d
+}
+
+// Advantage is 1 when the modifier wins the triangle, -1 when it loses and 0 otherwise
+func (m Modifier) Advantage() int {
+	switch {
+	case m.Hit > 0 || m.Damage > 0:
+		return 1
+	case m.Hit < 0 || m.Damage < 0:
+		return -1
+	}
+	return 0
+}
@@ -0,0 +1,86 @@
+package combat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Physical triangle at +-15 hit and +-1 damage
+var testTriangle = TriangleMatrix{
+	SWORD: {AXE: {Hit: 15, Damage: 1}, LANCE: {Hit: -15, Damage: -1}},
+	AXE:   {LANCE: {Hit: 15, Damage: 1}, SWORD: {Hit: -15, Damage: -1}},
+	LANCE: {SWORD: {Hit: 15, Damage: 1}, AXE: {Hit: -15, Damage: -1}},
+}
+
+func armed(id int, weaponType WeaponType) Combatant {
+	c := fighter(id, [2]int{0, id})
+	c.Weapon.Type = weaponType
+	return c
+}
+
+func TestTriangleModifiesHitAndDamage(t *testing.T) {
+	tests := []struct {
+		name      string
+		attacker  WeaponType
+		defender  WeaponType
+		damage    int
+		hit       int
+		advantage int
+	}{
+		{"sword beats axe", SWORD, AXE, 9, 100, 1},
+		{"axe beats lance", AXE, LANCE, 9, 100, 1},
+		{"lance beats sword", LANCE, SWORD, 9, 100, 1},
+		{"sword loses to lance", SWORD, LANCE, 7, 74, -1},
+		{"lance loses to axe", LANCE, AXE, 7, 74, -1},
+		{"same type", SWORD, SWORD, 8, 89, 0},
+		{"bow is neutral", BOW, SWORD, 8, 89, 0},
+		{"untyped is neutral", "", LANCE, 8, 89, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			a, d := Face(armed(0, tt.attacker), armed(1, tt.defender), testTriangle)
+
+			// Then
+			assert.Equal(t, tt.damage, Damage(a, d))
+			assert.Equal(t, tt.hit, HitRate(a, d))
+			assert.Equal(t, tt.advantage, a.Triangle.Advantage())
+		})
+	}
+}
+
+func TestTriangleIsOnlyWhatTheMatrixSays(t *testing.T) {
+	// Given a matrix where only the magic triangle matters
+	m := TriangleMatrix{ANIMA: {LIGHT: {Hit: 10}}}
+
+	// Then
+	assert.Equal(t, Modifier{Hit: 10}, m.Bonus(Weapon{Type: ANIMA}, Weapon{Type: LIGHT}))
+	assert.Equal(t, Modifier{}, m.Bonus(Weapon{Type: LIGHT}, Weapon{Type: ANIMA}))
+	assert.Equal(t, Modifier{}, m.Bonus(Weapon{Type: SWORD}, Weapon{Type: AXE}))
+	assert.Equal(t, 1, m.Bonus(Weapon{Type: ANIMA}, Weapon{Type: LIGHT}).Advantage())
+	assert.Equal(t, Modifier{}, TriangleMatrix(nil).Bonus(Weapon{Type: SWORD}, Weapon{Type: AXE}))
+}
+
+func TestForecast(t *testing.T) {
+	// Given a lance user attacking a faster sword user
+	a := armed(0, LANCE)
+	d := armed(1, SWORD)
+	d.Spd = a.Spd + DoubleAttackSpeed
+	a, d = Face(a, d, testTriangle)
+
+	// When
+	sut := NewForecast(a, d)
+
+	// Then
+	assert.Equal(t, Side{HP: 20, Damage: 9, Hit: 96, Crit: 0, Strikes: 1, Advantage: 1}, sut.Attacker)
+	assert.Equal(t, Side{HP: 20, Damage: 7, Hit: 74, Crit: 0, Strikes: 2, Advantage: -1}, sut.Defender)
+
+	// When the defender can't reach
+	a.Weapon.MinRange, a.Weapon.MaxRange = 2, 2
+	a.Pos = [2]int{0, 3}
+
+	// Then
+	assert.Equal(t, 0, NewForecast(a, d).Defender.Strikes)
+}
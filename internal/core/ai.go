@@ -0,0 +1,30 @@
+package core
+
+import (
+	"openFE/internal/ai"
+	"openFE/internal/battle"
+)
+
+// Frames between two ai units acting so the player can follow what happens
+const aiDelay = 30
+
+func (g *Game) UpdateAI() {
+	if !g.Options.FastAI && g.Count%aiDelay != 0 {
+		return
+	}
+	u := g.MG.Battle.NextAIUnit()
+	if u == nil {
+		g.Apply(battle.Command{Type: battle.ENDPHASE})
+		return
+	}
+	for _, cmd := range battle.AICommands(ai.Decide(g.MG.Battle.AIBoard(), u.ID())) {
+		if _, err := g.Apply(cmd); err != nil {
+			// Decide only picks legal moves, if it didn't the unit still has to give up its turn
+			Logger.Println(err)
+			g.Apply(battle.Command{Type: battle.WAIT, UnitID: u.ID()})
+			break
+		}
+	}
+	g.MG.pc.SetPrevCursor(g.MG.pc.posXY)
+	g.MG.pc.posXY = u.Pos()
+}
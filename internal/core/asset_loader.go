@@ -2,18 +2,25 @@ package core
 
 import (
 	"log"
+	"os"
+	"path/filepath"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/solarlune/ldtkgo"
+
+	"openFE/internal/battle"
 )
 
+const LdtkPath = "assets/demo/8x8.ldtk"
+
 var (
 	LdtkProject      *ldtkgo.Project
-	FloorSprite      *ebiten.Image
 	UnitSprite       *ebiten.Image
 	CursorSprite     *ebiten.Image
 	ActionMenuSprite *ebiten.Image
+	ItemIconSprite   *ebiten.Image // 16x16 icons in a row, indexed by ItemData.Icon
+	JobSprites       = map[battle.Job]*ebiten.Image{}
 )
 
 func LoadSpritesheets() {
@@ -29,14 +36,43 @@ func LoadSpritesheets() {
 		log.Fatal(err)
 	}
 
-	LdtkProject, err = ldtkgo.Open("assets/demo/8x8.ldtk")
+	data, err := os.ReadFile(LdtkPath)
 	if err != nil {
 		panic("Map file doesn't exist")
 	}
+	LdtkProject, err = ldtkgo.Read(data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	layerOpacity, err = readLayerOpacities(data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, tileset := range LdtkProject.Tilesets {
+		TilesetSprites[tileset.ID], _, err = ebitenutil.NewImageFromFile(tilesetPath(LdtkPath, tileset))
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
 
-	FloorSprite, _, err = ebitenutil.NewImageFromFile("assets/demo/experiment.png")
+	if err := battle.LoadJobs(battle.JobsDir); err != nil {
+		log.Fatal(err)
+	}
+	if err := battle.LoadItems(battle.ItemsDir); err != nil {
+		log.Fatal(err)
+	}
+	if err := battle.LoadTriangle(battle.TriangleFile); err != nil {
+		log.Fatal(err)
+	}
+	ItemIconSprite, _, err = ebitenutil.NewImageFromFile(filepath.Join(battle.ItemsDir, "icons.png"))
 	if err != nil {
-		panic("Tilemap doesn't exist")
+		log.Fatal(err)
+	}
+	for job, data := range battle.Jobs {
+		JobSprites[job], _, err = ebitenutil.NewImageFromFile(filepath.Join(battle.JobsDir, data.Sprite))
+		if err != nil {
+			log.Fatal(err)
+		}
 	}
 
 	ActionMenuSprite, _, err = ebitenutil.NewImageFromFile("assets/demo/actionmenu_sprites.png")
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"slices"
+
+	"openFE/internal/battle"
+)
+
+// Apply plays cmd on the battle with the game's rng. History is committed
+// once a command finishes an action so undo steps over whole actions, item
+// changes made from the item menu are undone one at a time. Taking a move
+// back is free and never becomes an undo step.
+func (g *Game) Apply(cmd battle.Command) ([]battle.Event, error) {
+	events, err := g.MG.Battle.Apply(cmd, g.Rng)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range events {
+		Logger.Println(e)
+	}
+	g.MG.Observe(events)
+	g.record(cmd)
+	if cmd.Type != battle.SELECT && cmd.Type != battle.MOVE && cmd.Type != battle.UNMOVE {
+		g.Commit()
+	}
+	// A replay only covers one level, playback stops on the cleared map
+	if slices.ContainsFunc(events, func(e battle.Event) bool { return e.Type == battle.CLEARED }) && g.Playback == nil {
+		if err := g.NextLevel(); err != nil {
+			Logger.Println(err)
+		}
+	}
+	return events, nil
+}
+
+// Observe updates what the player sees after the battle played a command
+func (mg *MGrid) Observe(events []battle.Event) {
+	for _, e := range events {
+		switch e.Type {
+		case battle.SELECTED:
+			u := mg.Battle.Units[e.UnitID]
+			mg.ClearThreat()
+			mg.SetSelectedUnit(u.ID())
+			mg.SelectForMove(u)
+			mg.SetState(UNITMOVEMENT)
+
+		case battle.MOVED:
+			if mg.selectedUnit == e.UnitID {
+				mg.SetState(UNITACTIONS)
+			}
+
+		// The unit picks where to go again from where it started
+		case battle.UNMOVED:
+			if mg.selectedUnit == e.UnitID {
+				mg.SelectForMove(mg.Battle.Units[e.UnitID])
+				mg.SetState(UNITMOVEMENT)
+			}
+
+		case battle.WAITED:
+			if mg.selectedUnit == e.UnitID {
+				mg.ClearSelectedUnit()
+				mg.targets = []*battle.Unit{}
+				mg.SetState(SELECTUNIT)
+			}
+
+		case battle.PHASEENDED:
+			mg.ClearThreat()
+			if mg.selectedUnit != notSelected {
+				mg.ClearSelectedUnit()
+				mg.SetState(SELECTUNIT)
+			}
+		}
+	}
+}
@@ -1,9 +1,5 @@
 package core
 
-const (
-	GRIDSIZE int = 8
-)
-
 const (
 	TileSize float64 = 16
 	// ScreenWidth  int     = 256 * 2
@@ -28,7 +24,14 @@ type TurnState int
 const (
 	SELECTUNIT TurnState = iota
 	UNITMOVEMENT
-	UNITACTIONS // Unused for now
+	UNITWALK // Selected unit is walking to where it was sent, input is ignored
+	UNITACTIONS
+	SELECTTARGET
+	ITEMMENU    // Selected unit's item menu is open
+	TRADEMENU   // Selected unit trades items with an adjacent ally
+	UNITINFO    // A unit's stat sheet is open
+	PAUSEMENU   // Opened on an empty tile, ends the turn or opens the options
+	OPTIONSMENU // Settings opened from the pause menu
 )
 
 const (
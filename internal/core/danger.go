@@ -0,0 +1,66 @@
+package core
+
+import (
+	"image/color"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"openFE/internal/battle"
+)
+
+// Danger zone is every tile an enemy of the player could attack next turn. It
+// is cached and only recomputed after something moves or dies.
+type DangerZone struct {
+	Show    bool
+	tiles   []PosXY
+	dirty   bool
+	changes int   // Battle.Changes() when tiles were computed
+	marked  []int // Unit ids, while any of them is alive only these enemies are counted
+}
+
+func (mg *MGrid) InvalidateDangerZone() {
+	mg.danger.dirty = true
+}
+
+func (mg *MGrid) ToggleDangerZone() {
+	mg.danger.Show = !mg.danger.Show
+}
+
+// ToggleMark adds or removes an enemy from the danger zone selection
+func (mg *MGrid) ToggleMark(u *battle.Unit) {
+	if i := slices.Index(mg.danger.marked, u.ID()); i != -1 {
+		mg.danger.marked = slices.Delete(mg.danger.marked, i, i+1)
+	} else {
+		mg.danger.marked = append(mg.danger.marked, u.ID())
+	}
+	mg.InvalidateDangerZone()
+}
+
+func (mg *MGrid) IsMarked(u *battle.Unit) bool {
+	return slices.Contains(mg.danger.marked, u.ID())
+}
+
+func (mg *MGrid) DangerZone() []PosXY {
+	if mg.danger.dirty || mg.danger.tiles == nil || mg.danger.changes != mg.Battle.Changes() {
+		mg.danger.tiles = mg.Battle.DangerZone(mg.danger.marked)
+		mg.danger.changes = mg.Battle.Changes()
+		mg.danger.dirty = false
+	}
+	return mg.danger.tiles
+}
+
+func (mg *MGrid) RenderDangerZone(screen *ebiten.Image, offsetX, offsetY float64, count int) {
+	if !mg.danger.Show {
+		return
+	}
+	f32cameraScale := float32(CAMERASCALE)
+	f32offsetX := float32(offsetX)
+	f32offsetY := float32(offsetY)
+	for _, pos := range mg.DangerZone() {
+		x0y0 := mg.grid[pos[Y]][pos[X]].x0y0
+		color := color.RGBA{R: 120, G: 0, B: 160, A: 3}
+		vector.DrawFilledRect(screen, float32(x0y0[X])+f32offsetX, float32(x0y0[Y])+f32offsetY, 16*f32cameraScale, 16*f32cameraScale, color, true)
+	}
+}
@@ -0,0 +1,31 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"openFE/internal/battle"
+)
+
+func TestDangerZoneRecomputedAfterMove(t *testing.T) {
+	// Given
+	p := testUnit(0, battle.PLAYER, PosXY{0, 0}, 1)
+	e := testUnit(1, battle.ENEMY, PosXY{5, 0}, 1)
+	mg := testMGrid([]string{"........"}, []*battle.Unit{p, e})
+	before := mg.DangerZone()
+
+	// When
+	mg.Battle.SetUnitPos(e, PosXY{2, 0})
+	sut := mg.DangerZone()
+
+	// Then
+	assert.NotEqual(t, before, sut)
+	assert.Contains(t, sut, PosXY{0, 0})
+
+	// When
+	mg.Battle.KillUnit(e)
+
+	// Then
+	assert.Empty(t, mg.DangerZone())
+}
@@ -2,12 +2,15 @@ package core
 
 import (
 	"fmt"
-	"image"
 	"slices"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"openFE/internal/battle"
+	"openFE/internal/rng"
 )
 
 func DebugMessages(screen *ebiten.Image, mg *MGrid) {
@@ -20,6 +23,13 @@ func DebugMessages(screen *ebiten.Image, mg *MGrid) {
 	ebitenutil.DebugPrintAt(screen, pc_str, pX, 64)
 	CAMERASCALE := fmt.Sprintf("CameraScale: [%f]", CAMERASCALE)
 	ebitenutil.DebugPrintAt(screen, CAMERASCALE, pX, 80)
+	ebitenutil.DebugPrintAt(screen, "E to end turn", pX, 96)
+	ebitenutil.DebugPrintAt(screen, "F/M Danger zone/Mark enemy", pX, 128)
+	ebitenutil.DebugPrintAt(screen, "F5/F9 Quick save/load, F6 Save replay", pX, 144)
+	ebitenutil.DebugPrintAt(screen, "Backspace/Esc Cancel", pX, 160)
+	ebitenutil.DebugPrintAt(screen, "I Unit info, Enter on empty tile Pause", pX, 176)
+	turn_str := fmt.Sprintf("Turn %d: %s phase", mg.Battle.Turn(), mg.Battle.Phase())
+	ebitenutil.DebugPrintAt(screen, turn_str, pX, 112)
 }
 
 type Game struct {
@@ -27,13 +37,14 @@ type Game struct {
 	Camera        Camera
 	MG            MGrid
 	Count         int
-	History       []MGrid
+	History       []Snapshot
 	ActionCounter int
 	MenuManager   MenuManager
-}
-
-func (g *Game) AppendHistory(mg MGrid) {
-	g.History = append(g.History, mg)
+	Options       Options
+	Rng           *rng.RNG       // Every hit/crit roll comes from here so battles are reproducible from the seed
+	Replay        *battle.Replay // Set while recording
+	Playback      *Playback      // Set while a replay plays, player input is ignored
+	commands      int            // Commands applied since the recording started
 }
 
 func (g *Game) IncrementActionCounter() {
@@ -59,37 +70,44 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	cameraOffsetX = g.Camera.X * 16 * -1
 	cameraOffsetY = g.Camera.Y * 16 * -1
 
-	for _, tile := range LdtkProject.Levels[0].Layers[1].Tiles {
-		x0 := float64(tile.Position[0])
-		y0 := float64(tile.Position[1])
-		op := &ebiten.DrawImageOptions{}
-
-		// No idea why I needed to divide by camerascale
-		// in order to fix zoomin zoomout when I didn't need to do that for unitsprite
-		op.GeoM.Translate(float64(x0+cameraOffsetX/CAMERASCALE), float64(y0+cameraOffsetY/CAMERASCALE))
-		op.GeoM.Scale(float64(CAMERASCALE), float64(CAMERASCALE))
-		screen.DrawImage(FloorSprite.SubImage(image.Rect(tile.Src[0], tile.Src[1], tile.Src[0]+16, tile.Src[1]+16)).(*ebiten.Image), op)
+	// Levels without tile layers only show the grid
+	layers := LevelTileLayers(LdtkProject.LevelByIdentifier(g.MG.Battle.Level()))
+	for i := range layers {
+		if !layers[i].Overlay {
+			layers[i].Draw(screen, cameraOffsetX, cameraOffsetY)
+		}
 	}
 
 	RenderGrid(screen, &g.MG, cameraOffsetX, cameraOffsetY)
-	if g.MG.turnState == UNITMOVEMENT {
+	g.MG.RenderDangerZone(screen, cameraOffsetX, cameraOffsetY, g.Count)
+	if g.MG.turnState == UNITMOVEMENT || (g.MG.turnState == SELECTUNIT && g.MG.threatUnit != notSelected) {
 		g.MG.RenderLegalPositions(screen, cameraOffsetX, cameraOffsetY, g.Count)
+		g.MG.RenderAttackPositions(screen, cameraOffsetX, cameraOffsetY, g.Count)
 	}
 
-	if g.MG.turnState == UNITACTIONS {
-		if g.MG.selectedUnit == notSelected { // Make sure a unit is selected
-			g.MG.turnState = SELECTUNIT
-		} else {
-			u := g.MG.Units[g.MG.selectedUnit]
-			g.MenuManager.ActionMenu.DrawMenu(screen, u.rd.x0y0, cameraOffsetX, cameraOffsetY, g.Count)
+	if g.MG.turnState == UNITMOVEMENT {
+		g.MG.RenderPath(screen, cameraOffsetX, cameraOffsetY, g.Count)
+	}
+
+	if g.MG.turnState == SELECTTARGET {
+		g.MG.RenderTargets(screen, cameraOffsetX, cameraOffsetY, g.Count)
+	}
+
+	g.MG.RenderUnits(screen, cameraOffsetX, cameraOffsetY, g.Count)
+	for i := range layers {
+		if layers[i].Overlay {
+			layers[i].Draw(screen, cameraOffsetX, cameraOffsetY)
 		}
 	}
 	g.MG.RenderCursor(screen, cameraOffsetX, cameraOffsetY, g.Count)
-	g.MG.RenderUnits(screen, cameraOffsetX, cameraOffsetY, g.Count)
+	g.MG.RenderTerrainWindow(screen)
+	g.MenuManager.Draw(screen, g)
 	DebugMessages(screen, &g.MG)
 }
 
 func (g *Game) Update() error {
+	// Commands, undo and the ai change the turn state, the menus follow it
+	defer g.MenuManager.Sync(g)
 	g.Keys = inpututil.AppendPressedKeys(g.Keys[:0])
 	g.Count++
 	SetGridCellCoord(&g.MG, MapStartingX0, MapStartingY0)
@@ -107,6 +125,21 @@ func (g *Game) Update() error {
 		CAMERASCALE *= .5
 	}
 
+	if g.Playback != nil {
+		g.UpdatePlayback()
+		g.MoveCamera()
+		return nil
+	}
+
+	// Input is ignored until the walk is over, then the actions can be picked
+	if g.MG.turnState == UNITWALK {
+		if g.MG.UpdateWalk() {
+			g.MG.pc.SetColor(GREEN)
+			g.MG.SetState(UNITACTIONS)
+		}
+		return nil
+	}
+
 	if inpututil.IsKeyJustPressed(ebiten.KeyW) {
 		g.MG.pc.MoveCursorUp()
 	}
@@ -124,99 +157,150 @@ func (g *Game) Update() error {
 	}
 
 	if inpututil.IsKeyJustPressed(ebiten.KeyC) {
-		fmt.Println("Undo is pressed")
-		g.DeincrementActionCounter()
-		g.MG = g.History[g.ActionCounter]
+		g.Undo()
 	}
 
 	if inpututil.IsKeyJustPressed(ebiten.KeyV) {
-		fmt.Println("Redo is pressed")
-		g.IncrementActionCounter()
-		g.MG = g.History[g.ActionCounter]
+		g.Redo()
+	}
+
+	// Quick save only between actions, a half done move isn't part of the save
+	if inpututil.IsKeyJustPressed(ebiten.KeyF5) && g.MG.Battle.Phase() == battle.PLAYER && g.MG.turnState == SELECTUNIT {
+		if err := g.Save(SavePath(QuickSaveSlot)); err != nil {
+			Logger.Println("Quick save failed:", err)
+		} else {
+			Logger.Println("Quick saved")
+		}
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyF9) {
+		if err := g.Load(SavePath(QuickSaveSlot)); err != nil {
+			Logger.Println("Quick load failed:", err)
+		} else {
+			Logger.Println("Quick loaded")
+		}
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyF6) {
+		path := ReplayPath(time.Now().Format("20060102-150405"))
+		if err := g.SaveReplay(path); err != nil {
+			Logger.Println("Saving replay failed:", err)
+		} else {
+			Logger.Println("Replay saved to", path)
+		}
 	}
 
 	if inpututil.IsKeyJustPressed(ebiten.KeyP) {
-		fmt.Println("debugger triggered")
+		Logger.Println("debugger triggered")
+	}
+
+	// Enemy and other phases are played by the ai
+	if g.MG.Battle.Phase() != battle.PLAYER {
+		g.UpdateAI()
+	}
+
+	if g.MG.Battle.Phase() == battle.PLAYER && g.MG.turnState == SELECTUNIT && inpututil.IsKeyJustPressed(ebiten.KeyE) {
+		g.Apply(battle.Command{Type: battle.ENDPHASE})
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyF) {
+		g.MG.ToggleDangerZone()
+	}
+
+	// Mark the enemy under the cursor so the danger zone only shows marked enemies
+	if inpututil.IsKeyJustPressed(ebiten.KeyM) {
+		unitId := g.MG.Battle.UnitAt(g.MG.pc.posXY)
+		if unitId != emptyCell && !g.MG.Battle.Units[unitId].Faction().IsAlly(battle.PLAYER) {
+			g.MG.ToggleMark(g.MG.Battle.Units[unitId])
+		}
 	}
 
 	enterPressed := inpututil.IsKeyJustPressed(ebiten.KeyEnter)
 
 	// Pick which character to move
-	if g.MG.turnState == SELECTUNIT && enterPressed {
+	if g.MG.Battle.Phase() == battle.PLAYER && g.MG.turnState == SELECTUNIT && enterPressed {
 		cursor_posXY := g.MG.pc.posXY
-		cell := g.MG.QueryCell(cursor_posXY)
-		if cell.unitId != notSelected {
-			g.MG.SetSelectedUnit(cell.unitId)
-			g.MG.pc.SetColor(BLUE)
-			legalPositions := reachableCells(&g.MG, cursor_posXY, GRIDSIZE, 3)
-			g.MG.legalPositions = legalPositions
-			g.MG.SetState(UNITMOVEMENT)
+		unitId := g.MG.Battle.UnitAt(cursor_posXY)
+		if unitId != emptyCell && !g.MG.Battle.Units[unitId].Faction().IsAlly(battle.PLAYER) {
+			// Enemies can't be moved, selecting one shows what it threatens instead
+			g.MG.ToggleThreat(g.MG.Battle.Units[unitId])
+		} else if unitId != emptyCell {
+			if _, err := g.Apply(battle.Command{Type: battle.SELECT, UnitID: unitId}); err != nil {
+				Logger.Println(err)
+			} else {
+				g.MG.pc.SetColor(BLUE)
+			}
 		} else {
-			fmt.Println("No unit found at the selected position")
+			g.MG.ClearThreat()
+			g.MenuManager.PauseMenu.Open()
+			g.MenuManager.Push(g, &g.MenuManager.PauseMenu)
 		}
 
 		enterPressed = false
 	}
 
+	// The stat sheet of the unit under the cursor, or of the one picking its action
+	if g.MG.Battle.Phase() == battle.PLAYER && inpututil.IsKeyJustPressed(ebiten.KeyI) && (g.MG.turnState == SELECTUNIT || g.MG.turnState == UNITACTIONS) {
+		unitId := g.MG.Battle.UnitAt(g.MG.pc.posXY)
+		if g.MG.turnState == UNITACTIONS {
+			unitId = g.MG.selectedUnit
+		}
+		if unitId != emptyCell {
+			g.MenuManager.UnitInfo.Open(g.MG.Battle.Units[unitId])
+			g.MenuManager.Push(g, &g.MenuManager.UnitInfo)
+		}
+	}
+
+	if g.MG.turnState == UNITMOVEMENT && cancelPressed() {
+		g.MG.CancelSelect()
+	}
+
+	if g.MG.turnState == UNITMOVEMENT {
+		g.MG.SteerPath(g.MG.Battle.Units[g.MG.selectedUnit], g.MG.pc.posXY)
+	}
+
 	// Click where to move for picked character
 	if g.MG.turnState == UNITMOVEMENT && enterPressed {
 		cursor_posXY := g.MG.pc.posXY
-		// Note: might be removed
-		cursor_posX := cursor_posXY[X]
-		cursor_posY := cursor_posXY[Y]
-		// --
 		selectedUnitId := g.MG.selectedUnit
-		selectedUnit := g.MG.Units[selectedUnitId]
-		if selectedUnit.posXY[X] == cursor_posX && selectedUnit.posXY[Y] == cursor_posY {
-			fmt.Println("clicked tile is on the same tile as selected unit, wasting action")
-			g.MG.ClearSelectedUnit()
-			g.MG.pc.SetColor(GREEN)
-			g.MG.SetState(SELECTUNIT)
-		} else if slices.Contains(g.MG.legalPositions, cursor_posXY) {
-			fmt.Println("legalMove")
-			g.MG.SetUnitPos(selectedUnit, cursor_posXY)
-			g.MG.pc.SetColor(GREEN)
-			g.MG.SetState(SELECTUNIT)
-			g.AppendHistory(g.MG)
-			g.MG.Units[selectedUnit.id].posXYAppendHistory(cursor_posXY)
-			// g.MG.ClearSelectedUnit() // This will need to be moved
-			g.ActionCounter += 1
-			g.MG.SetState(UNITACTIONS)
-		} else {
-			fmt.Println("not legalMove")
+		selectedUnit := g.MG.Battle.Units[selectedUnitId]
+		// Staying on the unit's own tile is a move too, it still gets its actions
+		if slices.Contains(g.MG.legalPositions, cursor_posXY) {
+			// The unit already stands on its new cell, the walk only animates getting there
+			if _, err := g.Apply(battle.Command{Type: battle.MOVE, UnitID: selectedUnitId, To: cursor_posXY}); err != nil {
+				Logger.Println(err)
+			} else {
+				g.MG.StartWalk(selectedUnit)
+			}
 		}
 
 		enterPressed = false
 	}
 
-	// Select what to do after moving
-	if g.MG.turnState == UNITACTIONS {
-		// fmt.Println("select actions for player")
-		g.MenuManager.ActionMenu.Update()
-		if enterPressed {
-			g.MG.ClearSelectedUnit()
-			g.MG.turnState = SELECTUNIT
-			enterPressed = false
-		}
-	}
+	// Menus opened after moving, only the top one gets the input
+	g.MenuManager.Update(g, enterPressed)
 
 	// Camera Movement should lock depending on state and do something else
 	// Note: Currently kinda scuffed needs camera to be moved to selectedUnit if it is away
-	if g.MG.turnState != UNITACTIONS {
-		for _, keyPress := range g.Keys {
-			switch keyPress {
-			case ebiten.KeyUp:
-				g.Camera.Y += -.25
-			case ebiten.KeyDown:
-				g.Camera.Y += .25
-			case ebiten.KeyLeft:
-				g.Camera.X += -.25
-			case ebiten.KeyRight:
-				g.Camera.X += .25
-			default:
-			}
-		}
+	if g.MenuManager.Top() == nil {
+		g.MoveCamera()
 	}
 
 	return nil
 }
+
+func (g *Game) MoveCamera() {
+	for _, keyPress := range g.Keys {
+		switch keyPress {
+		case ebiten.KeyUp:
+			g.Camera.Y += -.25
+		case ebiten.KeyDown:
+			g.Camera.Y += .25
+		case ebiten.KeyLeft:
+			g.Camera.X += -.25
+		case ebiten.KeyRight:
+			g.Camera.X += .25
+		default:
+		}
+	}
+}
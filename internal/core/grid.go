@@ -3,106 +3,50 @@ package core
 import (
 	"image"
 	"image/color"
+	"slices"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
-	"github.com/solarlune/ldtkgo"
 	"golang.org/x/image/math/f64"
-)
-
-// Notes: fix gridSize here, will need to be removed
-func reachableCells(mg *MGrid, pos PosXY, gridSize, maxMoveDistance int) []PosXY {
-	var directions = []PosXY{
-		{0, -1}, // Up
-		{0, 1},  // Down
-		{-1, 0}, // Left
-		{1, 0},  // Right
-	}
-
-	row_len := gridSize
-	col_len := gridSize
-
-	queue := []PosXY{pos}
-
-	visited := make([][]bool, row_len)
-	distance := make([][]int, row_len)
-	for i := 0; i < row_len; i++ {
-		visited[i] = make([]bool, col_len)
-		distance[i] = make([]int, col_len)
-	}
-
-	visited[pos[Y]][pos[X]] = true
-	distance[pos[Y]][pos[X]] = 0 // Start at 0 distance
-
-	legalPositions := []PosXY{}
 
-	for len(queue) > 0 {
-		current := queue[0]
-		// Dequeue the first element to start the loop
-		queue = queue[1:]
-
-		col, row := current[X], current[Y]
-
-		// If the current position's distance is less than maxMoveDistance, add it to legal positions
-		if distance[row][col] <= maxMoveDistance {
-			legalPositions = append(legalPositions, PosXY{col, row})
-		}
-
-		// If we've reached the maxMoveDistance, stop expanding further from this tile
-		if distance[row][col] >= maxMoveDistance {
-			continue
-		}
-
-		// Explore all neighboring tiles
-		for _, direction := range directions {
-			adjacentCol, adjacentRow := col+direction[X], row+direction[Y]
-
-			// Check if the adjacent cell is within bounds and hasn't been visited
-			// Checks if an object is blocking path, any number thats not 0 on intgrid is an obj
-			if adjacentRow >= 0 && adjacentCol >= 0 && adjacentRow < row_len && adjacentCol < col_len && !visited[adjacentRow][adjacentCol] && mg.grid[adjacentRow][adjacentCol].cellType != 1 {
-				visited[adjacentRow][adjacentCol] = true
-				distance[adjacentRow][adjacentCol] = distance[row][col] + 1
-				queue = append(queue, PosXY{adjacentCol, adjacentRow})
-			}
-		}
-	}
-	return legalPositions
-}
+	"openFE/internal/battle"
+	"openFE/internal/pathfind"
+)
 
-const emptyCell = -1
+const emptyCell = battle.EmptyCell
 
+// GridCell is where a cell of the battle's map is drawn
 type GridCell struct {
-	cellId   int
-	x0y0     f64.Vec2
-	unitId   int
-	cellType int // ldtk intgrid
-}
-
-// Will prob delete
-func (gc *GridCell) ClearUnit() {
-	gc.unitId = emptyCell
+	cellId int
+	x0y0   f64.Vec2
 }
 
 const notSelected = -1
 
+// MGrid draws a battle and holds what the player is doing with it (cursor,
+// selection, overlays). Rules live in Battle, MGrid only reacts to its events.
 type MGrid struct {
-	turnState      TurnState
-	grid           [][]GridCell
-	pc             PlayerCursor
-	Units          []*Unit
-	selectedUnit   int // UnitID, it is -1 if there is no selected unit
-	legalPositions []PosXY
+	Battle          *battle.Battle
+	turnState       TurnState
+	grid            [][]GridCell
+	pc              PlayerCursor
+	selectedUnit    int // UnitID, it is -1 if there is no selected unit
+	legalPositions  []PosXY
+	attackPositions []PosXY // Red overlay, tiles that can be attacked from legalPositions
+	moveTree        pathfind.Tree
+	path            []PosXY // Arrow from the selected unit to the cursor
+	walk            *Walk   // Set while the selected unit walks its path in UNITWALK
+	threatUnit      int     // Enemy whose range is shown during SELECTUNIT, -1 if none
+	danger          DangerZone
+	targets         []*battle.Unit // Units the selected unit can attack, filled in SELECTTARGET
 }
 
 func (mg *MGrid) SearchUnit() {
 }
 
-// Note: removing GridSize
-func CreateMGrid(units []*Unit, cursorSprite *ebiten.Image, mapFile *ldtkgo.Project) MGrid {
-	// Note: Layer0 is intgrid Layer1 is tileset data
-	intGrid := LdtkProject.Levels[0].Layers[0]
-	gridWidth := LdtkProject.Levels[0].Layers[0].CellWidth
-	gridLength := LdtkProject.Levels[0].Layers[0].CellHeight
+func CreateMGrid(b *battle.Battle, cursorSprite *ebiten.Image) MGrid {
+	gridWidth := b.Width()
+	gridLength := b.Height()
 
 	cellId := 0
 	grid := make([][]GridCell, gridLength)
@@ -111,37 +55,11 @@ func CreateMGrid(units []*Unit, cursorSprite *ebiten.Image, mapFile *ldtkgo.Proj
 		for j := 0; j < gridWidth; j++ {
 			grid[i][j] = GridCell{
 				cellId: cellId,
-				unitId: emptyCell,
 			}
 			cellId += 1
 		}
 	}
 
-	for _, i := range intGrid.IntGrid {
-		x := i.ID % gridWidth
-		y := i.ID / gridWidth
-		grid[y][x].cellType = i.Value
-	}
-
-	/*
-		grid := make([][]GridCell, gridSize)
-		for i := 0; i < gridSize; i++ {
-			grid[i] = make([]GridCell, gridSize) // Initialize each row
-			for j := 0; j < gridSize; j++ {
-				grid[i][j] = GridCell{
-					unitId: emptyCell,
-					// cellType: cellType,
-				}
-			}
-		}
-	*/
-
-	for _, u := range units {
-		pX := u.posXY[X]
-		pY := u.posXY[Y]
-		grid[pY][pX].unitId = u.id
-	}
-
 	posXY := PosXY{0, 0}
 	GridCellStartingX0 := MapStartingX0 + (float64(16*posXY[X]) - 2)
 	GridCellStartingY0 := MapStartingY0 + (float64(16*posXY[Y]) - 2)
@@ -154,36 +72,35 @@ func CreateMGrid(units []*Unit, cursorSprite *ebiten.Image, mapFile *ldtkgo.Proj
 	}
 
 	mgrid := MGrid{
-		turnState:      SELECTUNIT,
-		grid:           grid,
-		pc:             PlayerCursor{PosXY{0, 0}, PosXY{0, 0}, color.RGBA{R: 0, G: 255, B: 0, A: 255}, rd},
-		Units:          units,
-		selectedUnit:   notSelected,
-		legalPositions: []PosXY{},
+		Battle:          b,
+		turnState:       SELECTUNIT,
+		grid:            grid,
+		pc:              PlayerCursor{PosXY{0, 0}, PosXY{0, 0}, PosXY{gridWidth, gridLength}, color.RGBA{R: 0, G: 255, B: 0, A: 255}, rd},
+		selectedUnit:    notSelected,
+		legalPositions:  []PosXY{},
+		attackPositions: []PosXY{},
+		threatUnit:      notSelected,
+		targets:         []*battle.Unit{},
 	}
 
 	SetGridCellCoord(&mgrid, MapStartingX0, MapStartingY0)
+	// A battle saved mid action picks up in the unit's action menu, like undo does
+	if u := b.MidAction(); u != nil {
+		mgrid.SetSelectedUnit(u.ID())
+		mgrid.SetState(UNITACTIONS)
+	}
 	return mgrid
 }
 
-func (mg *MGrid) ClearGridCell(pX, pY int) {
-	mg.grid[pY][pX].ClearUnit()
+// Screen position of a cell, before the camera offset
+func (mg *MGrid) cellXY(posXY PosXY) f64.Vec2 {
+	return mg.grid[posXY[Y]][posXY[X]].x0y0
 }
 
 func (mg *MGrid) SetState(ts TurnState) {
 	mg.turnState = ts
 }
 
-func (mg *MGrid) QueryCell(posXY PosXY) GridCell {
-	pX := posXY[X]
-	pY := posXY[Y]
-	return mg.grid[pY][pX]
-}
-
-func (mg *MGrid) QueryUnit(pX, pY int) int {
-	return mg.grid[pY][pX].unitId
-}
-
 func (mg *MGrid) SetSelectedUnit(id int) {
 	mg.selectedUnit = id
 }
@@ -213,27 +130,19 @@ func (mg *MGrid) RenderCursor(screen *ebiten.Image, offsetX, offsetY float64, co
 }
 
 func (mg *MGrid) RenderUnits(screen *ebiten.Image, offsetX, offsetY float64, count int) {
-	for _, unit := range mg.Units {
-		pX := unit.posXY[X]
-		pY := unit.posXY[Y]
-		unit.rd.x0y0 = mg.grid[pY][pX].x0y0
-		unit.IdleAnimation(screen, offsetX, offsetY, count)
+	for _, unit := range mg.Battle.Units {
+		if unit.IsDead() {
+			continue
+		}
+		if mg.walk != nil && mg.walk.unit == unit {
+			x0y0, direction := mg.walk.Position(mg)
+			WalkAnimation(screen, unit, x0y0, offsetX, offsetY, count, direction)
+			continue
+		}
+		IdleAnimation(screen, unit, mg.cellXY(unit.Pos()), offsetX, offsetY, count)
 	}
 }
 
-func (mg *MGrid) SetUnitPos(u *Unit, new_posXY PosXY) {
-	new_pX := new_posXY[X]
-	new_pY := new_posXY[Y]
-	mg.ClearGridCell(u.posXY[X], u.posXY[Y])
-	newGridCellPos := &mg.grid[new_pY][new_pX]
-
-	// New grid location
-	newGridCellPos.unitId = u.id
-	u.rd.x0y0 = newGridCellPos.x0y0
-	newPos := PosXY{new_pX, new_pY}
-	u.posXY = newPos
-}
-
 func SetGridCellCoord(mg *MGrid, startingX0, startingY0 float64) {
 	incX := float64(0)
 	incY := float64(0)
@@ -242,7 +151,7 @@ func SetGridCellCoord(mg *MGrid, startingX0, startingY0 float64) {
 			x0 := startingX0 + incX
 			y0 := startingY0 + incY
 			mg.grid[row][col].x0y0 = f64.Vec2{x0, y0}
-			if col < GRIDSIZE-1 {
+			if col < len(mg.grid[row])-1 {
 				incX += 16 * CAMERASCALE // No Idea why I needed to multiply this
 			} else {
 				incX = 0
@@ -270,6 +179,72 @@ func (mg *MGrid) RenderLegalPositions(screen *ebiten.Image, offsetX, offsetY flo
 	}
 }
 
+func (mg *MGrid) RenderAttackPositions(screen *ebiten.Image, offsetX, offsetY float64, count int) {
+	f32cameraScale := float32(CAMERASCALE)
+	f32offsetX := float32(offsetX)
+	f32offsetY := float32(offsetY)
+	for _, pos := range mg.attackPositions {
+		x0y0 := mg.grid[pos[Y]][pos[X]].x0y0
+		color := color.RGBA{R: 255, G: 0, B: 25, A: 3}
+		vector.DrawFilledRect(screen, float32(x0y0[X])+f32offsetX, float32(x0y0[Y])+f32offsetY, 16*f32cameraScale, 16*f32cameraScale, color, true)
+	}
+}
+
+// ToggleThreat shows (or hides if it's already shown) the move and attack range of u
+func (mg *MGrid) ToggleThreat(u *battle.Unit) {
+	if mg.threatUnit == u.ID() {
+		mg.ClearThreat()
+		return
+	}
+	mg.threatUnit = u.ID()
+	mg.legalPositions = mg.Battle.Reachable(u)
+	mg.attackPositions = mg.Battle.AttackRange(u, mg.legalPositions)
+}
+
+func (mg *MGrid) ClearThreat() {
+	mg.threatUnit = notSelected
+	mg.legalPositions = []PosXY{}
+	mg.attackPositions = []PosXY{}
+}
+
+// Target is the attack target under the cursor, nil if the cursor isn't on one
+func (mg *MGrid) Target() *battle.Unit {
+	if !mg.Battle.InBounds(mg.pc.posXY) {
+		return nil
+	}
+	unitId := mg.Battle.UnitAt(mg.pc.posXY)
+	i := slices.IndexFunc(mg.targets, func(u *battle.Unit) bool { return u.ID() == unitId })
+	if i == -1 {
+		return nil
+	}
+	return mg.targets[i]
+}
+
+// CycleTarget moves the cursor step targets along, wrapping around
+func (mg *MGrid) CycleTarget(step int) {
+	if len(mg.targets) == 0 {
+		return
+	}
+	i := slices.Index(mg.targets, mg.Target())
+	if i == -1 {
+		i = 0
+	} else {
+		i = (i + step + len(mg.targets)) % len(mg.targets)
+	}
+	mg.pc.posXY = mg.targets[i].Pos()
+}
+
+func (mg *MGrid) RenderTargets(screen *ebiten.Image, offsetX, offsetY float64, count int) {
+	f32cameraScale := float32(CAMERASCALE)
+	f32offsetX := float32(offsetX)
+	f32offsetY := float32(offsetY)
+	for _, u := range mg.targets {
+		x0y0 := mg.cellXY(u.Pos())
+		color := color.RGBA{R: 255, G: 0, B: 25, A: 5}
+		vector.DrawFilledRect(screen, float32(x0y0[X])+f32offsetX, float32(x0y0[Y])+f32offsetY, 16*f32cameraScale, 16*f32cameraScale, color, true)
+	}
+}
+
 // For visualization
 func (mg *MGrid) _RenderLegalPositions(screen *ebiten.Image, offsetX, offsetY float64, count int) {
 	if len(mg.legalPositions) == 0 {
@@ -300,7 +275,7 @@ func RenderGrid(screen *ebiten.Image, mg *MGrid, offsetX, offsetY float64) {
 			x0 := MapStartingX0 + offsetX + incX
 			y0 := MapStartingY0 + offsetY + incY
 			vector.StrokeRect(screen, float32(x0), float32(y0), 16*f32cameraScale, 16*f32cameraScale, 1, color.White, true)
-			if col < GRIDSIZE-1 {
+			if col < len(mg.grid[row])-1 {
 				incX += 16 * CAMERASCALE
 			} else {
 				incX = 0
@@ -314,6 +289,7 @@ func RenderGrid(screen *ebiten.Image, mg *MGrid, offsetX, offsetY float64) {
 type PlayerCursor struct {
 	posXY        PosXY
 	prevXY       PosXY
+	bounds       PosXY       // Map width and height, cursor stays inside it
 	_cursorColor color.Color //unused rn
 	rd           RenderData
 }
@@ -373,7 +349,7 @@ func (pc *PlayerCursor) MoveCursorLeft() {
 
 func (pc *PlayerCursor) MoveCursorDown() {
 	pY := &pc.posXY[Y]
-	if *pY < GRIDSIZE-1 {
+	if *pY < pc.bounds[Y]-1 {
 		pc.SetPrevCursor(pc.posXY)
 		*pY += 1
 	}
@@ -381,7 +357,7 @@ func (pc *PlayerCursor) MoveCursorDown() {
 
 func (pc *PlayerCursor) MoveCursorRight() {
 	pX := &pc.posXY[X]
-	if *pX < GRIDSIZE-1 {
+	if *pX < pc.bounds[X]-1 {
 		pc.SetPrevCursor(pc.posXY)
 		*pX += 1
 	}
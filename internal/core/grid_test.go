@@ -4,49 +4,52 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"openFE/internal/battle"
+	"openFE/internal/combat"
 )
 
-func add(a, b int) int {
-	return a + b
+// Builds a grid without ldtk, '.' plains, '#' wall, 'f' forest, 'm' mountain, '~' water
+func testMGrid(rows []string, units []*battle.Unit) MGrid {
+	runes := map[rune]battle.Terrain{'.': battle.PLAINS, '#': battle.WALL, 'f': battle.FOREST, 'm': battle.MOUNTAIN, '~': battle.WATER}
+	terrain := make([][]battle.Terrain, len(rows))
+	for y, row := range rows {
+		for _, c := range row {
+			terrain[y] = append(terrain[y], runes[c])
+		}
+	}
+	return CreateMGrid(battle.New("test", terrain, units), nil)
 }
 
-func TestAdd(t *testing.T) {
-	// Given
-	a := 1
-	b := 2
-
-	// When
-	r := add(a, b)
-
-	// Then
-	assert.Equal(t, r, 3)
+func testUnit(id int, faction battle.Faction, posXY PosXY, movement int) *battle.Unit {
+	r := battle.RPG{
+		Job:      "test",
+		Movement: movement,
+		Level:    1,
+		HP:       20,
+		Stats:    battle.Stats{HP: 20, Str: 6, Mag: 1, Skl: 5, Spd: 7, Lck: 4, Def: 4, Res: 2, Con: 7},
+		Weapon:   combat.Weapon{Name: "Iron Sword", Might: 5, Hit: 70, Crit: 10, MinRange: 1, MaxRange: 1},
+	}
+	return battle.NewUnit(id, r, faction, posXY)
 }
 
-// [[1 0] [1 2] [0 1] [2 1] [0 0] [2 0]]
-
-// [(0 0) | (0 1) | (0 2)]
-// [(1 0) | (1 1) | (1 2)]
-// [(2 0) | (2 1) | (2 2)]
-/*
-func TestReachableCells(t *testing.T) {
+func TestToggleThreat(t *testing.T) {
 	// Given
-	maxMoveDistance := 1
-	gridSize := 3
-	unitInfo := RPG{Job: NOBLE, Movement: 2}
-	u := CreateUnit(0, UnitSprite, unitInfo, PosXY{0, 1})
-	i := CreateUnit(1, UnitSprite, unitInfo, PosXY{1, 0})
-
-	units := []Unit{u, i}
-	unitPointers := make([]*Unit, len(units))
+	p := testUnit(0, battle.PLAYER, PosXY{0, 0}, 1)
+	e := testUnit(1, battle.ENEMY, PosXY{2, 0}, 1)
+	mg := testMGrid([]string{"....."}, []*battle.Unit{p, e})
 
-	grid := CreateMGrid(unitPointers, gridSize)
+	// When
+	mg.ToggleThreat(e)
 
-	expected_legalPositions := []PosXY{{0, 1}, {2, 1}, {1, 0}, {1, 2}}
+	// Then
+	assert.ElementsMatch(t, []PosXY{{1, 0}, {2, 0}, {3, 0}}, mg.legalPositions)
+	assert.ElementsMatch(t, []PosXY{{0, 0}, {4, 0}}, mg.attackPositions)
 
 	// When
-	sut := reachableCells(&grid, PosXY{1, 1}, maxMoveDistance)
+	mg.ToggleThreat(e)
 
 	// Then
-	assert.Equal(t, sut, expected_legalPositions)
+	assert.Equal(t, notSelected, mg.threatUnit)
+	assert.Empty(t, mg.attackPositions)
 }
-*/
@@ -0,0 +1,83 @@
+package core
+
+import "openFE/internal/battle"
+
+// Snapshot is one undo step, everything needed to put the battle back as it was
+type Snapshot struct {
+	Battle   *battle.Battle
+	Rng      uint64
+	Commands int // Commands applied up to this point, see Game.record
+}
+
+// History entries get their own copy of the battle so they never share state
+// with the live game
+func (g *Game) snapshot() Snapshot {
+	s := Snapshot{Battle: g.MG.Battle.Clone(), Commands: g.commands}
+	if g.Rng != nil {
+		s.Rng = g.Rng.State()
+	}
+	return s
+}
+
+// Snapshots are taken between actions or after an item change, whatever the
+// player was doing is dropped. A unit caught mid action gets its menu back.
+func (g *Game) restore(s Snapshot) {
+	g.MG.Battle = s.Battle.Clone()
+	g.MG.SetState(SELECTUNIT)
+	g.MG.ClearSelectedUnit()
+	if u := g.MG.Battle.MidAction(); u != nil {
+		g.MG.SetSelectedUnit(u.ID())
+		g.MG.SetState(UNITACTIONS)
+	}
+	g.MG.ClearThreat()
+	g.MG.targets = []*battle.Unit{}
+	g.MG.path = []PosXY{}
+	g.MG.walk = nil
+	g.MG.InvalidateDangerZone()
+	g.commands = s.Commands
+	if g.Rng != nil {
+		g.Rng.SetState(s.Rng)
+	}
+}
+
+// Commit records the current state as the newest history entry. Acting after
+// an undo drops the entries that were undone.
+func (g *Game) Commit() {
+	if len(g.History) > 0 {
+		g.History = g.History[:g.ActionCounter+1]
+	}
+	g.History = append(g.History, g.snapshot())
+	g.ActionCounter = len(g.History) - 1
+}
+
+// Drops every history entry, the current state becomes the only one
+func (g *Game) resetHistory() {
+	g.History = []Snapshot{}
+	g.ActionCounter = 0
+	g.commands = 0
+	g.Commit()
+}
+
+// Commands were applied since the current history entry was committed
+func (g *Game) uncommitted() bool {
+	return g.commands != g.History[g.ActionCounter].Commands
+}
+
+func (g *Game) Undo() {
+	if len(g.History) == 0 {
+		return
+	}
+	// Moves aren't committed, a unit caught mid action only loses its move
+	if !g.uncommitted() {
+		g.DeincrementActionCounter()
+	}
+	g.restore(g.History[g.ActionCounter])
+}
+
+func (g *Game) Redo() {
+	if len(g.History) == 0 {
+		return
+	}
+	g.IncrementActionCounter()
+	g.restore(g.History[g.ActionCounter])
+}
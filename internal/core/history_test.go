@@ -0,0 +1,130 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"openFE/internal/battle"
+	"openFE/internal/rng"
+)
+
+func testGame() (*Game, *battle.Unit, *battle.Unit) {
+	p := testUnit(0, battle.PLAYER, PosXY{0, 0}, 3)
+	e := testUnit(1, battle.ENEMY, PosXY{3, 0}, 3)
+	g := &Game{
+		MG:      testMGrid([]string{"....", "...."}, []*battle.Unit{p, e}),
+		History: []Snapshot{},
+		Rng:     rng.New(1),
+	}
+	g.Commit()
+	return g, p, e
+}
+
+func TestUndoRedoRoundTrip(t *testing.T) {
+	// Given
+	g, p, e := testGame()
+
+	// When the player moves and attacks, which ends the phase
+	_, err := g.Apply(battle.Command{Type: battle.MOVE, UnitID: p.ID(), To: PosXY{2, 0}})
+	assert.NoError(t, err)
+	_, err = g.Apply(battle.Command{Type: battle.ATTACK, UnitID: p.ID(), TargetID: e.ID()})
+	assert.NoError(t, err)
+	hp := g.MG.Battle.Units[e.ID()].RPG().HP
+
+	// When
+	g.Undo()
+
+	// Then everything is back, including turn state
+	assert.Equal(t, PosXY{0, 0}, g.MG.Battle.Units[p.ID()].Pos())
+	assert.Equal(t, p.ID(), g.MG.Battle.UnitAt(PosXY{0, 0}))
+	assert.Equal(t, emptyCell, g.MG.Battle.UnitAt(PosXY{2, 0}))
+	assert.Equal(t, 20, g.MG.Battle.Units[e.ID()].RPG().HP)
+	assert.Equal(t, battle.PLAYER, g.MG.Battle.Phase())
+	assert.False(t, g.MG.Battle.Units[p.ID()].Waited())
+	assert.Equal(t, SELECTUNIT, g.MG.turnState)
+
+	// When
+	g.Redo()
+
+	// Then
+	assert.Equal(t, PosXY{2, 0}, g.MG.Battle.Units[p.ID()].Pos())
+	assert.Equal(t, p.ID(), g.MG.Battle.UnitAt(PosXY{2, 0}))
+	assert.Equal(t, hp, g.MG.Battle.Units[e.ID()].RPG().HP)
+	assert.Equal(t, battle.ENEMY, g.MG.Battle.Phase())
+}
+
+func TestUndoAfterMoveOnlyRevertsMove(t *testing.T) {
+	// Given a finished action followed by a move
+	p := testUnit(0, battle.PLAYER, PosXY{0, 0}, 3)
+	other := testUnit(1, battle.PLAYER, PosXY{0, 1}, 3)
+	e := testUnit(2, battle.ENEMY, PosXY{3, 0}, 3)
+	g := &Game{MG: testMGrid([]string{"....", "...."}, []*battle.Unit{p, other, e}), History: []Snapshot{}, Rng: rng.New(1)}
+	g.Commit()
+	_, err := g.Apply(battle.Command{Type: battle.WAIT, UnitID: other.ID()})
+	assert.NoError(t, err)
+	_, err = g.Apply(battle.Command{Type: battle.MOVE, UnitID: p.ID(), To: PosXY{1, 0}})
+	assert.NoError(t, err)
+
+	// When
+	g.Undo()
+
+	// Then the wait stays
+	assert.Equal(t, PosXY{0, 0}, g.MG.Battle.Units[p.ID()].Pos())
+	assert.Nil(t, g.MG.Battle.MidAction())
+	assert.True(t, g.MG.Battle.Units[other.ID()].Waited())
+	assert.Equal(t, 1, g.ActionCounter)
+	assert.Equal(t, SELECTUNIT, g.MG.turnState)
+}
+
+func TestHistoryIsNotSharedWithLiveState(t *testing.T) {
+	// Given
+	g, p, _ := testGame()
+
+	// When the live state changes without committing
+	g.MG.Battle.SetUnitPos(p, PosXY{1, 1})
+
+	// Then the recorded entry didn't move
+	assert.Equal(t, PosXY{0, 0}, g.History[0].Battle.Units[p.ID()].Pos())
+	assert.Equal(t, p.ID(), g.History[0].Battle.UnitAt(PosXY{0, 0}))
+
+	// When restoring then changing the restored state
+	g.Undo()
+	g.MG.Battle.SetUnitPos(g.MG.Battle.Units[p.ID()], PosXY{2, 1})
+
+	// Then history still holds the original
+	assert.Equal(t, PosXY{0, 0}, g.History[0].Battle.Units[p.ID()].Pos())
+}
+
+func TestActingAfterUndoDropsRedo(t *testing.T) {
+	// Given
+	g, p, _ := testGame()
+	g.MG.Battle.SetUnitPos(p, PosXY{1, 0})
+	g.Commit()
+	g.MG.Battle.SetUnitPos(g.MG.Battle.Units[p.ID()], PosXY{2, 0})
+	g.Commit()
+	g.Undo()
+	g.Undo()
+
+	// When
+	g.MG.Battle.SetUnitPos(g.MG.Battle.Units[p.ID()], PosXY{0, 1})
+	g.Commit()
+	g.Redo()
+
+	// Then
+	assert.Len(t, g.History, 2)
+	assert.Equal(t, 1, g.ActionCounter)
+	assert.Equal(t, PosXY{0, 1}, g.MG.Battle.Units[p.ID()].Pos())
+}
+
+func TestUndoRestoresRng(t *testing.T) {
+	// Given
+	g, _, _ := testGame()
+	expected := []int{g.Rng.Intn(100), g.Rng.Intn(100)}
+
+	// When
+	g.Undo()
+
+	// Then
+	assert.Equal(t, expected, []int{g.Rng.Intn(100), g.Rng.Intn(100)})
+}
@@ -0,0 +1,10 @@
+package core
+
+import (
+	"log"
+	"os"
+)
+
+// Logger reports what the game did, battle events and errors the player can't
+// see on screen. Everything the core package prints goes through it.
+var Logger = log.New(os.Stdout, "", log.Ltime)
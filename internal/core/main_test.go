@@ -0,0 +1,25 @@
+package core
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"openFE/internal/battle"
+	"openFE/internal/combat"
+)
+
+// The fixtures are registered once so no test has to touch the registries,
+// the log stays quiet
+func TestMain(m *testing.M) {
+	Logger.SetOutput(io.Discard)
+	battle.Jobs = map[battle.Job]*battle.JobData{
+		"test": {Name: "Test", Movement: 5, MovementType: battle.INFANTRY, WeaponRanks: map[string]string{"lance": "D"}},
+	}
+	battle.Items = map[battle.ItemID]*battle.ItemData{
+		"vulnerary":  {Name: "Vulnerary", Uses: 3, Heal: 10},
+		"iron_lance": {Name: "Iron Lance", Uses: 45, Weapon: &combat.Weapon{Name: "Iron Lance", Type: combat.LANCE, Might: 7, Hit: 80, MinRange: 1, MaxRange: 1}},
+		"iron_sword": {Name: "Iron Sword", Uses: 46, Weapon: &combat.Weapon{Name: "Iron Sword", Type: combat.SWORD, Might: 5, Hit: 90, MinRange: 1, MaxRange: 1}},
+	}
+	os.Exit(m.Run())
+}
@@ -1,24 +1,172 @@
 package core
 
 import (
+	"fmt"
 	"image"
 	"image/color"
+	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 	"golang.org/x/image/math/f64"
+
+	"openFE/internal/battle"
+	"openFE/internal/combat"
+)
+
+// Colors shared by the windows drawn in screen space
+var (
+	windowColor    = color.RGBA{R: 25, G: 0, B: 80, A: 180}
+	highlightColor = color.RGBA{R: 255, G: 255, B: 255, A: 60}
 )
 
+// Menu is one window on the MenuManager's stack
+type Menu interface {
+	State() TurnState                   // Turn state while the menu is on top
+	Update(g *Game, enter bool)         // Reads input, only the top menu gets any
+	Cancel(g *Game) bool                // Backs out one step, true pops the menu
+	Draw(screen *ebiten.Image, g *Game) // Menus are drawn bottom to top
+}
+
+// Turn states that belong to a menu
+var menuStates = map[TurnState]bool{
+	UNITACTIONS: true, ITEMMENU: true, SELECTTARGET: true, TRADEMENU: true,
+	UNITINFO: true, PAUSEMENU: true, OPTIONSMENU: true,
+}
+
+// Menus acting for the selected unit, the action menu is under them
+var unitMenuStates = map[TurnState]bool{UNITACTIONS: true, ITEMMENU: true, SELECTTARGET: true, TRADEMENU: true}
+
+// MenuManager keeps the open menus on a stack. Only the top menu gets input
+// and cancelling it pops back to the one under it.
 type MenuManager struct {
-	// MenuStack  []int // Enums to keep track of which menu's are on top of each other
-	ActionMenu ActionMenu
+	Stack       []Menu
+	ActionMenu  ActionMenu
+	ItemMenu    ItemMenu
+	TargetMenu  TargetMenu
+	TradeMenu   TradeMenu
+	UnitInfo    UnitInfo
+	PauseMenu   PauseMenu
+	OptionsMenu OptionsMenu
+}
+
+// Top is the menu getting input, nil when no menu is open
+func (mm *MenuManager) Top() Menu {
+	if len(mm.Stack) == 0 {
+		return nil
+	}
+	return mm.Stack[len(mm.Stack)-1]
+}
+
+// Push opens m on top of the other menus
+func (mm *MenuManager) Push(g *Game, m Menu) {
+	mm.Stack = append(mm.Stack, m)
+	g.MG.SetState(m.State())
+}
+
+// Pop closes the top menu, the one under it takes over
+func (mm *MenuManager) Pop(g *Game) {
+	if len(mm.Stack) == 0 {
+		return
+	}
+	mm.Stack = mm.Stack[:len(mm.Stack)-1]
+	if top := mm.Top(); top != nil {
+		g.MG.SetState(top.State())
+	} else if menuStates[g.MG.turnState] {
+		g.MG.SetState(SELECTUNIT)
+	}
+}
+
+// Close pops every menu, the player picks a unit again
+func (mm *MenuManager) Close(g *Game) {
+	mm.Stack = nil
+	g.MG.SetState(SELECTUNIT)
+}
+
+// Cancel backs out of the top menu, popping it once there is nothing left to back out of
+func (mm *MenuManager) Cancel(g *Game) {
+	if top := mm.Top(); top != nil && top.Cancel(g) {
+		mm.Pop(g)
+	}
+}
+
+// Sync lines the stack up with states set outside of it. Ending an action
+// closes every menu and undo can land back in the action menu.
+func (mm *MenuManager) Sync(g *Game) {
+	state := g.MG.turnState
+	if !menuStates[state] || (unitMenuStates[state] && g.MG.selectedUnit == notSelected) {
+		mm.Stack = nil
+		if menuStates[state] {
+			g.MG.SetState(SELECTUNIT)
+		}
+		return
+	}
+	for len(mm.Stack) > 0 && mm.Top().State() != state {
+		mm.Stack = mm.Stack[:len(mm.Stack)-1]
+	}
+	if len(mm.Stack) > 0 {
+		return
+	}
+	if unitMenuStates[state] {
+		mm.Push(g, &mm.ActionMenu)
+	} else {
+		g.MG.SetState(SELECTUNIT) // Nothing opened the menu, there is nothing to show
+	}
+}
+
+// Update hands the input to the top menu
+func (mm *MenuManager) Update(g *Game, enter bool) {
+	mm.Sync(g)
+	top := mm.Top()
+	if top == nil {
+		return
+	}
+	if cancelPressed() {
+		mm.Cancel(g)
+		return
+	}
+	top.Update(g, enter)
+}
+
+func (mm *MenuManager) Draw(screen *ebiten.Image, g *Game) {
+	for _, m := range mm.Stack {
+		m.Draw(screen, g)
+	}
+}
+
+// Backspace and escape back out of menus, X already zooms out
+func cancelPressed() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyBackspace) || inpututil.IsKeyJustPressed(ebiten.KeyEscape)
+}
+
+// Moves selected through a list of count lines with the up and down arrows
+func scrollList(selected *int, count int) {
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowUp) && *selected > 0 {
+		*selected -= 1
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowDown) && *selected < count-1 {
+		*selected += 1
+	}
+}
+
+// Draws lines in a window, selected is highlighted, -1 highlights nothing
+func drawList(screen *ebiten.Image, x0, y0, width int, lines []string, selected int) {
+	vector.DrawFilledRect(screen, float32(x0), float32(y0), float32(width), float32(len(lines)*lineHeight+8), windowColor, true)
+	for i, line := range lines {
+		y := y0 + 4 + i*lineHeight
+		if i == selected {
+			vector.DrawFilledRect(screen, float32(x0), float32(y), float32(width), lineHeight, highlightColor, true)
+		}
+		ebitenutil.DebugPrintAt(screen, line, x0+4, y)
+	}
 }
 
 type ActionMenu struct {
 	MenuOptions []string
-	Selected    int // Index of selected option
-	rds         []RenderData
+	Selected    int                   // Index of selected option
+	rds         map[string]RenderData // Option -> its icon, options without one show their first letter
 	//fadeInFrames int // Fade-in duration
 	//frameCount   int // Tracks number of elapsed frames for fade-in
 }
@@ -42,13 +190,73 @@ func CreateActionMenu(spritesheet *ebiten.Image) ActionMenu {
 		spritesheet: spritesheet,
 	}
 
-	rds := []RenderData{icon0_rd, icon1_rd, icon2_rd}
+	rds := map[string]RenderData{"attack": icon0_rd, "items": icon1_rd, "skip": icon2_rd}
 
-	actionMenu := ActionMenu{MenuOptions: []string{"attack", "items", "skip"}, Selected: 0, rds: rds}
+	actionMenu := ActionMenu{MenuOptions: []string{"attack", "items", "trade", "skip"}, Selected: 0, rds: rds}
 	return actionMenu
 }
 
-func (m *ActionMenu) Update() {
+func (m *ActionMenu) State() TurnState { return UNITACTIONS }
+
+// Update picks what the selected unit does after moving
+func (m *ActionMenu) Update(g *Game, enter bool) {
+	m.Scroll()
+	if !enter {
+		return
+	}
+	mm := &g.MenuManager
+	u := g.MG.Battle.Units[g.MG.selectedUnit]
+	switch m.MenuOptions[m.Selected] {
+	case "attack":
+		targets := g.MG.Battle.AttackTargets(u)
+		if len(targets) == 0 {
+			Logger.Println("No targets in range")
+			return
+		}
+		g.MG.targets = targets
+		g.MG.pc.SetPrevCursor(g.MG.pc.posXY)
+		g.MG.pc.posXY = targets[0].Pos()
+		g.MG.pc.SetColor(RED)
+		mm.Push(g, &mm.TargetMenu)
+	case "items":
+		if len(u.Items()) == 0 {
+			Logger.Println("No items")
+			return
+		}
+		mm.ItemMenu.Open()
+		mm.Push(g, &mm.ItemMenu)
+	case "trade":
+		partners := g.MG.Battle.TradePartners(u)
+		if len(partners) == 0 {
+			Logger.Println("No one to trade with")
+			return
+		}
+		mm.TradeMenu.Open(g, partners)
+		mm.Push(g, &mm.TradeMenu)
+	default:
+		g.Apply(battle.Command{Type: battle.WAIT, UnitID: u.ID()})
+	}
+}
+
+// Cancel takes the move back, the unit gets its move range again
+func (m *ActionMenu) Cancel(g *Game) bool {
+	id := g.MG.selectedUnit
+	if _, err := g.Apply(battle.Command{Type: battle.UNMOVE, UnitID: id}); err != nil {
+		Logger.Println(err)
+		return false
+	}
+	g.MG.pc.posXY = g.MG.Battle.Units[id].Pos()
+	g.MG.pc.SetColor(BLUE)
+	return true
+}
+
+func (m *ActionMenu) Draw(screen *ebiten.Image, g *Game) {
+	u := g.MG.Battle.Units[g.MG.selectedUnit]
+	m.DrawMenu(screen, g.MG.cellXY(u.Pos()), g.Camera.X*16*-1, g.Camera.Y*16*-1, g.Count)
+}
+
+// Scroll moves the selection with the left and right arrows
+func (m *ActionMenu) Scroll() {
 	isArrowLeftPressed := inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft)
 	if isArrowLeftPressed {
 		if m.Selected != 0 {
@@ -78,37 +286,282 @@ func (m *ActionMenu) DrawMenu(screen *ebiten.Image, x0y0 f64.Vec2, offsetX, offs
 	startLocationX := float32(x0y0[X]) + f32offsetX - padX
 	color := color.RGBA{R: 25, G: 0, B: 255, A: 5}
 
-	square0 := []float32{startLocationX, float32(x0y0[Y]) + f32offsetY + padY}
-	square1 := []float32{startLocationX + padXgap, float32(x0y0[Y]) + f32offsetY + padY}
-	square2 := []float32{startLocationX + (padXgap * 2), float32(x0y0[Y]) + f32offsetY + padY}
-	vector.DrawFilledRect(screen, square0[X], square0[Y], 8*f32cameraScale, 8*f32cameraScale, color, true)
-	vector.DrawFilledRect(screen, square1[X], square1[Y], 8*f32cameraScale, 8*f32cameraScale, color, true)
-	vector.DrawFilledRect(screen, square2[X], square2[Y], 8*f32cameraScale, 8*f32cameraScale, color, true)
-
-	m.IdleAnimation(screen, count, square0[X], square0[Y], 0)
-	m.IdleAnimation(screen, count, square1[X], square1[Y], 1)
-	m.IdleAnimation(screen, count, square2[X], square2[Y], 2)
+	for i := range m.MenuOptions {
+		square := []float32{startLocationX + (padXgap * float32(i)), float32(x0y0[Y]) + f32offsetY + padY}
+		vector.DrawFilledRect(screen, square[X], square[Y], 8*f32cameraScale, 8*f32cameraScale, color, true)
+		m.IdleAnimation(screen, count, square[X], square[Y], i)
+	}
 }
 
 func (m *ActionMenu) IdleAnimation(screen *ebiten.Image, count int, x0, y0 float32, index int) {
+	option := m.MenuOptions[index]
+	rd, ok := m.rds[option]
+	if !ok {
+		label := strings.ToUpper(option[:1])
+		if m.Selected == index {
+			label = "[" + label + "]"
+			x0 -= 6
+		}
+		ebitenutil.DebugPrintAt(screen, label, int(x0)+2, int(y0)-2)
+		return
+	}
+
 	op := &ebiten.DrawImageOptions{}
 
-	cellX := m.rds[index].ad.sc.cellX
-	cellY := m.rds[index].ad.sc.cellY
+	cellX := rd.ad.sc.cellX
+	cellY := rd.ad.sc.cellY
 
 	if m.Selected == index {
 		op.GeoM.Scale(float64(CAMERASCALE/1.75), float64(CAMERASCALE/1.75))
 		op.GeoM.Translate(float64(x0)-CAMERASCALE, float64(y0)-CAMERASCALE)
 
-		i := (count / m.rds[index].ad.frameFrequency) % m.rds[index].ad.frameCount
-		sx, sy := m.rds[index].ad.sc.GetCol(cellX)+i*m.rds[index].ad.sc.frameWidth, m.rds[index].ad.sc.GetRow(cellY)
-		screen.DrawImage(m.rds[index].spritesheet.SubImage(image.Rect(sx, sy, sx+m.rds[index].ad.sc.frameWidth, sy+m.rds[index].ad.sc.frameHeight)).(*ebiten.Image), op)
+		i := (count / rd.ad.frameFrequency) % rd.ad.frameCount
+		sx, sy := rd.ad.sc.GetCol(cellX)+i*rd.ad.sc.frameWidth, rd.ad.sc.GetRow(cellY)
+		screen.DrawImage(rd.spritesheet.SubImage(image.Rect(sx, sy, sx+rd.ad.sc.frameWidth, sy+rd.ad.sc.frameHeight)).(*ebiten.Image), op)
 	} else {
 		op.GeoM.Scale(float64(CAMERASCALE/2), float64(CAMERASCALE/2))
 		op.GeoM.Translate(float64(x0), float64(y0))
 
 		i := 0
-		sx, sy := m.rds[index].ad.sc.GetCol(cellX)+i*m.rds[index].ad.sc.frameWidth, m.rds[index].ad.sc.GetRow(cellY)
-		screen.DrawImage(m.rds[index].spritesheet.SubImage(image.Rect(sx, sy, sx+m.rds[index].ad.sc.frameWidth, sy+m.rds[index].ad.sc.frameHeight)).(*ebiten.Image), op)
+		sx, sy := rd.ad.sc.GetCol(cellX)+i*rd.ad.sc.frameWidth, rd.ad.sc.GetRow(cellY)
+		screen.DrawImage(rd.spritesheet.SubImage(image.Rect(sx, sy, sx+rd.ad.sc.frameWidth, sy+rd.ad.sc.frameHeight)).(*ebiten.Image), op)
+	}
+}
+
+// What can be done with an item picked in the item menu
+const (
+	ITEMUSE     = "use"
+	ITEMEQUIP   = "equip"
+	ITEMDISCARD = "discard"
+)
+
+const itemMenuWidth = 150
+
+// ItemMenu lists the selected unit's items, picking one lists what can be done with it
+type ItemMenu struct {
+	Selected       int      // Index of the selected item
+	Actions        []string // Actions of the picked item, empty while an item is being picked
+	SelectedAction int
+}
+
+// ItemActions are the item menu actions that make sense for u's item
+func ItemActions(u *battle.Unit, item battle.Item) []string {
+	actions := []string{}
+	if item.IsUsable() {
+		actions = append(actions, ITEMUSE)
+	}
+	if u.CanWield(item) {
+		actions = append(actions, ITEMEQUIP)
+	}
+	return append(actions, ITEMDISCARD)
+}
+
+// Open resets the menu to the first item
+func (m *ItemMenu) Open() {
+	*m = ItemMenu{}
+}
+
+// Pick lists the actions of u's selected item
+func (m *ItemMenu) Pick(u *battle.Unit) {
+	m.Actions = ItemActions(u, u.Items()[m.Selected])
+	m.SelectedAction = 0
+}
+
+// Back closes the action list, returns false if there was none to close
+func (m *ItemMenu) Back() bool {
+	if len(m.Actions) == 0 {
+		return false
+	}
+	m.Actions = nil
+	return true
+}
+
+// Command is what the picked action does to the selected item of unit id
+func (m *ItemMenu) Command(id int) battle.Command {
+	types := map[string]battle.CommandType{ITEMUSE: battle.USEITEM, ITEMEQUIP: battle.EQUIP, ITEMDISCARD: battle.DISCARD}
+	return battle.Command{Type: types[m.Actions[m.SelectedAction]], UnitID: id, Item: m.Selected}
+}
+
+// Done goes back to picking an item once the inventory changed
+func (m *ItemMenu) Done(items []battle.Item) {
+	m.Actions = nil
+	m.Selected = max(0, min(m.Selected, len(items)-1))
+}
+
+func (m *ItemMenu) State() TurnState { return ITEMMENU }
+
+// Update uses, equips or discards one of the selected unit's items
+func (m *ItemMenu) Update(g *Game, enter bool) {
+	u := g.MG.Battle.Units[g.MG.selectedUnit]
+	m.Scroll(u.Items())
+	if !enter {
+		return
+	}
+	if len(m.Actions) == 0 {
+		m.Pick(u)
+		return
+	}
+	// Using an item ends the action, the other changes stay in the menu
+	if _, err := g.Apply(m.Command(u.ID())); err != nil {
+		Logger.Println(err)
+	}
+	m.Done(u.Items())
+	if g.MG.turnState == ITEMMENU && len(u.Items()) == 0 {
+		g.MenuManager.Pop(g)
+	}
+}
+
+// Cancel closes the action list first and the menu after
+func (m *ItemMenu) Cancel(g *Game) bool {
+	return !m.Back()
+}
+
+func (m *ItemMenu) Draw(screen *ebiten.Image, g *Game) {
+	m.DrawMenu(screen, g.MG.Battle.Units[g.MG.selectedUnit])
+}
+
+// Scroll moves whichever list is open with the up and down arrows
+func (m *ItemMenu) Scroll(items []battle.Item) {
+	if len(m.Actions) > 0 {
+		scrollList(&m.SelectedAction, len(m.Actions))
+	} else {
+		scrollList(&m.Selected, len(items))
+	}
+}
+
+// DrawMenu shows the items in the top left corner, the equipped weapon is marked with an E
+func (m *ItemMenu) DrawMenu(screen *ebiten.Image, u *battle.Unit) {
+	items := u.Items()
+	x0, y0 := 4, 4
+	vector.DrawFilledRect(screen, float32(x0), float32(y0), itemMenuWidth, float32(len(items)*lineHeight+8), windowColor, true)
+
+	for i, item := range items {
+		y := y0 + 4 + i*lineHeight
+		if i == m.Selected {
+			vector.DrawFilledRect(screen, float32(x0), float32(y), itemMenuWidth, lineHeight, highlightColor, true)
+		}
+		icon := item.Data().Icon * 16
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64(x0+2), float64(y))
+		screen.DrawImage(ItemIconSprite.SubImage(image.Rect(icon, 0, icon+16, 16)).(*ebiten.Image), op)
+
+		equipped := " "
+		if i == u.Equipped() {
+			equipped = "E"
+		}
+		line := fmt.Sprintf("%s %-12s %2d/%d", equipped, item.Data().Name, item.Uses, item.Data().Uses)
+		ebitenutil.DebugPrintAt(screen, line, x0+20, y)
+	}
+
+	// Actions of the picked item open next to it
+	if len(m.Actions) == 0 {
+		return
+	}
+	ax := x0 + itemMenuWidth + 2
+	ay := y0 + 4 + m.Selected*lineHeight
+	vector.DrawFilledRect(screen, float32(ax), float32(ay), 60, float32(len(m.Actions)*lineHeight), windowColor, true)
+	for i, action := range m.Actions {
+		if i == m.SelectedAction {
+			vector.DrawFilledRect(screen, float32(ax), float32(ay+i*lineHeight), 60, lineHeight, highlightColor, true)
+		}
+		ebitenutil.DebugPrintAt(screen, action, ax+4, ay+i*lineHeight)
+	}
+}
+
+// TargetMenu picks who to attack, the forecast follows the cursor
+type TargetMenu struct{}
+
+func (m *TargetMenu) State() TurnState { return SELECTTARGET }
+
+// Update jumps between targets with the arrows and attacks on enter
+func (m *TargetMenu) Update(g *Game, enter bool) {
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) {
+		g.MG.CycleTarget(-1)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) {
+		g.MG.CycleTarget(1)
+	}
+	if !enter {
+		return
+	}
+	if target := g.MG.Target(); target == nil {
+		Logger.Println("not a valid target")
+	} else {
+		g.Apply(battle.Command{Type: battle.ATTACK, UnitID: g.MG.selectedUnit, TargetID: target.ID()})
+		g.MG.pc.SetColor(GREEN)
+	}
+}
+
+// Cancel puts the cursor back on the unit
+func (m *TargetMenu) Cancel(g *Game) bool {
+	g.MG.targets = []*battle.Unit{}
+	g.MG.pc.posXY = g.MG.Battle.Units[g.MG.selectedUnit].Pos()
+	g.MG.pc.SetColor(BLUE)
+	return true
+}
+
+func (m *TargetMenu) Draw(screen *ebiten.Image, g *Game) {
+	if target := g.MG.Target(); target != nil {
+		attacker := g.MG.Battle.Units[g.MG.selectedUnit]
+		DrawForecast(screen, attacker, target, g.MG.Battle.Forecast(attacker, target))
+	}
+}
+
+const forecastWidth = 150
+
+// ForecastRows are the label, attacker and defender columns of the forecast
+// window. A side that can't strike back shows dashes, one that doubles shows x2.
+func ForecastRows(f combat.Forecast) [][3]string {
+	rows := [][3]string{{"HP", fmt.Sprint(f.Attacker.HP), fmt.Sprint(f.Defender.HP)}}
+	stats := []struct {
+		label string
+		value func(combat.Side) string
+	}{
+		{"DMG", func(s combat.Side) string {
+			if s.Strikes == 2 {
+				return fmt.Sprintf("%d x2", s.Damage)
+			}
+			return fmt.Sprint(s.Damage)
+		}},
+		{"HIT", func(s combat.Side) string { return fmt.Sprint(s.Hit) }},
+		{"CRT", func(s combat.Side) string { return fmt.Sprint(s.Crit) }},
+	}
+	for _, stat := range stats {
+		row := [3]string{stat.label, "--", "--"}
+		for i, s := range []combat.Side{f.Attacker, f.Defender} {
+			if s.Strikes > 0 {
+				row[i+1] = stat.value(s)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// Weapon triangle marker shown next to a name
+func advantageMark(advantage int) string {
+	switch advantage {
+	case 1:
+		return "+"
+	case -1:
+		return "-"
+	}
+	return ""
+}
+
+// DrawForecast shows what attacking defender would do in the top left corner
+func DrawForecast(screen *ebiten.Image, attacker, defender *battle.Unit, f combat.Forecast) {
+	rows := ForecastRows(f)
+	x0, y0 := 4, 4
+	colX := []int{x0 + 4, x0 + 44, x0 + 100}
+	height := (len(rows)+1)*lineHeight + 8
+	vector.DrawFilledRect(screen, float32(x0), float32(y0), forecastWidth, float32(height), windowColor, true)
+
+	ebitenutil.DebugPrintAt(screen, attacker.Name()+advantageMark(f.Attacker.Advantage), colX[1], y0+4)
+	ebitenutil.DebugPrintAt(screen, defender.Name()+advantageMark(f.Defender.Advantage), colX[2], y0+4)
+	for i, row := range rows {
+		for j, text := range row {
+			ebitenutil.DebugPrintAt(screen, text, colX[j], y0+4+(i+1)*lineHeight)
+		}
 	}
 }
@@ -0,0 +1,213 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"openFE/internal/battle"
+	"openFE/internal/combat"
+)
+
+func TestItemActions(t *testing.T) {
+	// Given
+	u := testUnit(0, battle.PLAYER, PosXY{0, 0}, 1)
+
+	// Then
+	assert.Equal(t, []string{ITEMUSE, ITEMDISCARD}, ItemActions(u, battle.NewItem("vulnerary")))
+	assert.Equal(t, []string{ITEMEQUIP, ITEMDISCARD}, ItemActions(u, battle.NewItem("iron_lance")))
+	assert.Equal(t, []string{ITEMDISCARD}, ItemActions(u, battle.NewItem("iron_sword")))
+}
+
+func TestItemMenuPickAndBack(t *testing.T) {
+	// Given
+	u := testUnit(0, battle.PLAYER, PosXY{0, 0}, 1)
+	u.GiveItem("iron_lance")
+	u.GiveItem("vulnerary")
+	items := u.Items()
+	m := ItemMenu{}
+	m.Open()
+	m.Selected = 1
+
+	// When
+	m.Pick(u)
+	m.SelectedAction = 1
+
+	// Then
+	assert.Equal(t, battle.Command{Type: battle.DISCARD, UnitID: 4, Item: 1}, m.Command(4))
+
+	// When the item is gone
+	m.Done(items[:1])
+
+	// Then the selection stays on the list
+	assert.Empty(t, m.Actions)
+	assert.Equal(t, 0, m.Selected)
+	assert.False(t, m.Back())
+
+	// When
+	m.Pick(u)
+
+	// Then
+	assert.True(t, m.Back())
+	assert.Empty(t, m.Actions)
+}
+
+func TestUndoItemChangeKeepsActionMenu(t *testing.T) {
+	// Given a unit that moved and equipped its second weapon
+	g, p, _ := testGame()
+	p.GiveItem("vulnerary")
+	p.GiveItem("iron_lance")
+	g.Commit()
+	_, err := g.Apply(battle.Command{Type: battle.MOVE, UnitID: p.ID(), To: PosXY{1, 0}})
+	assert.NoError(t, err)
+	_, err = g.Apply(battle.Command{Type: battle.EQUIP, UnitID: p.ID(), Item: 1})
+	assert.NoError(t, err)
+	_, err = g.Apply(battle.Command{Type: battle.DISCARD, UnitID: p.ID(), Item: 1})
+	assert.NoError(t, err)
+
+	// When
+	g.Undo()
+
+	// Then the discard is undone and the unit still has to act
+	u := g.MG.Battle.Units[p.ID()]
+	assert.Equal(t, []battle.Item{battle.NewItem("iron_lance"), battle.NewItem("vulnerary")}, u.Items())
+	assert.Equal(t, PosXY{1, 0}, u.Pos())
+	assert.Equal(t, UNITACTIONS, g.MG.turnState)
+	assert.Equal(t, p.ID(), g.MG.selectedUnit)
+}
+
+func TestForecastRows(t *testing.T) {
+	tests := []struct {
+		name     string
+		forecast combat.Forecast
+		expected [][3]string
+	}{
+		{
+			"both strike once",
+			combat.Forecast{
+				Attacker: combat.Side{HP: 20, Damage: 8, Hit: 89, Crit: 2, Strikes: 1},
+				Defender: combat.Side{HP: 18, Damage: 5, Hit: 60, Crit: 0, Strikes: 1},
+			},
+			[][3]string{{"HP", "20", "18"}, {"DMG", "8", "5"}, {"HIT", "89", "60"}, {"CRT", "2", "0"}},
+		},
+		{
+			"attacker doubles, defender can't reach",
+			combat.Forecast{
+				Attacker: combat.Side{HP: 20, Damage: 6, Hit: 70, Crit: 5, Strikes: 2},
+				Defender: combat.Side{HP: 25, Damage: 9, Hit: 80, Crit: 1, Strikes: 0},
+			},
+			[][3]string{{"HP", "20", "25"}, {"DMG", "6 x2", "--"}, {"HIT", "70", "--"}, {"CRT", "5", "--"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ForecastRows(tt.forecast))
+		})
+	}
+}
+
+func TestCycleTargetWraps(t *testing.T) {
+	// Given a unit between two enemies with the cursor off any target
+	p := testUnit(0, battle.PLAYER, PosXY{1, 0}, 2)
+	left := testUnit(1, battle.ENEMY, PosXY{0, 0}, 2)
+	right := testUnit(2, battle.ENEMY, PosXY{2, 0}, 2)
+	mg := testMGrid([]string{"...", "..."}, []*battle.Unit{p, left, right})
+	mg.targets = mg.Battle.AttackTargets(p)
+	mg.pc.posXY = PosXY{1, 1}
+	assert.Nil(t, mg.Target())
+
+	// When
+	mg.CycleTarget(1)
+
+	// Then it starts on the first target
+	assert.Equal(t, left.ID(), mg.Target().ID())
+
+	// When
+	mg.CycleTarget(-1)
+
+	// Then it wraps around
+	assert.Equal(t, right.ID(), mg.Target().ID())
+	assert.Equal(t, right.Pos(), mg.pc.posXY)
+}
+
+// Picks p and moves it like the player would, which opens the action menu
+func selectAndMove(t *testing.T, g *Game, p *battle.Unit, to PosXY) {
+	_, err := g.Apply(battle.Command{Type: battle.SELECT, UnitID: p.ID()})
+	assert.NoError(t, err)
+	_, err = g.Apply(battle.Command{Type: battle.MOVE, UnitID: p.ID(), To: to})
+	assert.NoError(t, err)
+	g.MenuManager.Sync(g)
+}
+
+func TestMenuStackPushPop(t *testing.T) {
+	// Given a unit that moved
+	g, p, _ := testGame()
+	p.GiveItem("vulnerary")
+	selectAndMove(t, g, p, PosXY{1, 0})
+	mm := &g.MenuManager
+
+	// When
+	mm.ItemMenu.Open()
+	mm.Push(g, &mm.ItemMenu)
+
+	// Then the item menu sits on the action menu
+	assert.Equal(t, []Menu{&mm.ActionMenu, &mm.ItemMenu}, mm.Stack)
+	assert.Equal(t, ITEMMENU, g.MG.turnState)
+
+	// When an item's actions are open
+	mm.ItemMenu.Pick(p)
+	mm.Cancel(g)
+
+	// Then cancel closes them before the menu
+	assert.Equal(t, Menu(&mm.ItemMenu), mm.Top())
+
+	// When
+	mm.Cancel(g)
+
+	// Then
+	assert.Equal(t, Menu(&mm.ActionMenu), mm.Top())
+	assert.Equal(t, UNITACTIONS, g.MG.turnState)
+}
+
+func TestCancelActionMenuReturnsToOrigin(t *testing.T) {
+	// Given a unit that moved
+	g, p, _ := testGame()
+	selectAndMove(t, g, p, PosXY{1, 0})
+
+	// When
+	g.MenuManager.Cancel(g)
+
+	// Then the unit is back with its move range shown, without using up history
+	u := g.MG.Battle.Units[p.ID()]
+	assert.Equal(t, PosXY{0, 0}, u.Pos())
+	assert.Nil(t, g.MG.Battle.MidAction())
+	assert.Empty(t, g.MenuManager.Stack)
+	assert.Equal(t, UNITMOVEMENT, g.MG.turnState)
+	assert.Equal(t, p.ID(), g.MG.selectedUnit)
+	assert.Contains(t, g.MG.legalPositions, PosXY{1, 0})
+	assert.Equal(t, PosXY{0, 0}, g.MG.pc.posXY)
+	assert.Len(t, g.History, 1)
+
+	// When it moves somewhere else instead
+	_, err := g.Apply(battle.Command{Type: battle.MOVE, UnitID: p.ID(), To: PosXY{0, 1}})
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, PosXY{0, 1}, g.MG.Battle.Units[p.ID()].Pos())
+}
+
+func TestSyncClosesMenusAfterWait(t *testing.T) {
+	// Given
+	g, p, _ := testGame()
+	selectAndMove(t, g, p, PosXY{1, 0})
+	assert.Len(t, g.MenuManager.Stack, 1)
+
+	// When
+	_, err := g.Apply(battle.Command{Type: battle.WAIT, UnitID: p.ID()})
+	assert.NoError(t, err)
+	g.MenuManager.Sync(g)
+
+	// Then
+	assert.Nil(t, g.MenuManager.Top())
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"openFE/internal/battle"
+)
+
+const (
+	pauseMenuWidth   = 100
+	optionsMenuWidth = 130
+)
+
+// What can be picked in the pause menu
+const (
+	PAUSEENDTURN = "end turn"
+	PAUSEOPTIONS = "options"
+	PAUSERESUME  = "resume"
+)
+
+var pauseOptions = []string{PAUSEENDTURN, PAUSEOPTIONS, PAUSERESUME}
+
+// PauseMenu opens on an empty tile during the player phase
+type PauseMenu struct {
+	Selected int
+}
+
+// Open resets the menu to its first option
+func (m *PauseMenu) Open() {
+	*m = PauseMenu{}
+}
+
+func (m *PauseMenu) State() TurnState { return PAUSEMENU }
+
+func (m *PauseMenu) Update(g *Game, enter bool) {
+	scrollList(&m.Selected, len(pauseOptions))
+	if !enter {
+		return
+	}
+	mm := &g.MenuManager
+	switch pauseOptions[m.Selected] {
+	case PAUSEENDTURN:
+		mm.Close(g)
+		g.Apply(battle.Command{Type: battle.ENDPHASE})
+	case PAUSEOPTIONS:
+		mm.OptionsMenu.Open()
+		mm.Push(g, &mm.OptionsMenu)
+	default:
+		mm.Pop(g)
+	}
+}
+
+func (m *PauseMenu) Cancel(g *Game) bool {
+	return true
+}
+
+func (m *PauseMenu) Draw(screen *ebiten.Image, g *Game) {
+	drawList(screen, 4, 4, pauseMenuWidth, pauseOptions, m.Selected)
+}
+
+// Options are the settings changed from the options menu
+type Options struct {
+	FastAI bool // The ai plays a unit every frame instead of waiting aiDelay frames
+}
+
+// OptionsMenu toggles the selected setting on enter
+type OptionsMenu struct {
+	Selected int
+}
+
+// Open resets the menu to its first setting
+func (m *OptionsMenu) Open() {
+	*m = OptionsMenu{}
+}
+
+// Lines are the settings with their current value
+func (m *OptionsMenu) Lines(g *Game) []string {
+	return []string{
+		"danger zone: " + onOff(g.MG.danger.Show),
+		"fast ai:     " + onOff(g.Options.FastAI),
+	}
+}
+
+// Toggle flips the selected setting
+func (m *OptionsMenu) Toggle(g *Game) {
+	switch m.Selected {
+	case 0:
+		g.MG.ToggleDangerZone()
+	case 1:
+		g.Options.FastAI = !g.Options.FastAI
+	}
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
+
+func (m *OptionsMenu) State() TurnState { return OPTIONSMENU }
+
+func (m *OptionsMenu) Update(g *Game, enter bool) {
+	scrollList(&m.Selected, len(m.Lines(g)))
+	if enter {
+		m.Toggle(g)
+	}
+}
+
+func (m *OptionsMenu) Cancel(g *Game) bool {
+	return true
+}
+
+func (m *OptionsMenu) Draw(screen *ebiten.Image, g *Game) {
+	drawList(screen, 4+pauseMenuWidth+2, 4, optionsMenuWidth, m.Lines(g), m.Selected)
+}
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"openFE/internal/battle"
+)
+
+func TestOptionsMenuStacksOnPauseMenu(t *testing.T) {
+	// Given the pause menu with options picked
+	g, _, _ := testGame()
+	mm := &g.MenuManager
+	mm.PauseMenu.Open()
+	mm.Push(g, &mm.PauseMenu)
+	mm.PauseMenu.Selected = 1
+
+	// When
+	mm.Top().Update(g, true)
+	mm.Top().Update(g, true)
+
+	// Then the danger zone setting was toggled from the options menu
+	assert.Equal(t, []Menu{&mm.PauseMenu, &mm.OptionsMenu}, mm.Stack)
+	assert.Equal(t, OPTIONSMENU, g.MG.turnState)
+	assert.Equal(t, []string{"danger zone: on", "fast ai:     off"}, mm.OptionsMenu.Lines(g))
+
+	// When
+	mm.Cancel(g)
+	mm.Cancel(g)
+
+	// Then
+	assert.Empty(t, mm.Stack)
+	assert.Equal(t, SELECTUNIT, g.MG.turnState)
+}
+
+func TestEndTurnFromPauseMenu(t *testing.T) {
+	// Given
+	g, _, _ := testGame()
+	mm := &g.MenuManager
+	mm.PauseMenu.Open()
+	mm.Push(g, &mm.PauseMenu)
+
+	// When
+	mm.Top().Update(g, true)
+
+	// Then
+	assert.Empty(t, mm.Stack)
+	assert.Equal(t, SELECTUNIT, g.MG.turnState)
+	assert.Equal(t, battle.ENEMY, g.MG.Battle.Phase())
+}
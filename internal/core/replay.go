@@ -0,0 +1,136 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"openFE/internal/battle"
+	"openFE/internal/rng"
+)
+
+const (
+	ReplaysDir    = "replays"
+	playbackDelay = 30 // Frames between two commands, fast forward plays one every frame
+)
+
+func ReplayPath(name string) string {
+	return filepath.Join(ReplaysDir, name+".json")
+}
+
+// StateHash fingerprints the battle and the rng, see battle.SaveData.Hash
+func (g *Game) StateHash() string {
+	return g.SaveData().Hash()
+}
+
+// StartRecording starts a new replay from the current state
+func (g *Game) StartRecording() {
+	g.Replay = &battle.Replay{Version: battle.ReplayVersion, Start: g.SaveData(), Commands: []battle.Command{}}
+	g.resetHistory() // Undoing past the start would leave commands the replay never saw
+}
+
+// Records cmd as the latest command, dropping commands that were undone
+func (g *Game) record(cmd battle.Command) {
+	if g.Replay != nil {
+		g.Replay.Commands = append(g.Replay.Commands[:g.commands], cmd)
+	}
+	g.commands += 1
+}
+
+// SaveReplay writes what has been recorded so far, undone commands left out
+func (g *Game) SaveReplay(path string) error {
+	if g.Replay == nil {
+		return fmt.Errorf("nothing is being recorded")
+	}
+	r := *g.Replay
+	r.Commands = r.Commands[:g.commands]
+	r.Hash = g.StateHash()
+	return r.Write(path)
+}
+
+// Puts g at the start of the replay, history starts there
+func startGame(g *Game, r *battle.Replay) error {
+	b, err := r.Start.Battle(LdtkProject)
+	if err != nil {
+		return err
+	}
+	g.MG = CreateMGrid(b, CursorSprite)
+	g.Rng = rng.New(r.Start.Rng)
+	g.resetHistory()
+	return nil
+}
+
+// Playback steps through a replay in game, input only controls the playback
+type Playback struct {
+	Replay *battle.Replay
+	Next   int // Index of the next command to apply
+	Paused bool
+	Fast   bool
+	frames int
+}
+
+// StartPlayback puts the game back where r started, recording stops
+func (g *Game) StartPlayback(r *battle.Replay) error {
+	if err := startGame(g, r); err != nil {
+		return err
+	}
+	g.Replay = nil
+	g.Playback = &Playback{Replay: r}
+	return nil
+}
+
+// Step applies the next command, false once the replay is over or broke
+func (g *Game) Step() bool {
+	p := g.Playback
+	if p.Next >= len(p.Replay.Commands) {
+		return false
+	}
+	cmd := p.Replay.Commands[p.Next]
+	p.Next += 1
+	if _, err := g.Apply(cmd); err != nil {
+		Logger.Printf("Replay broke at command %d: %s", p.Next-1, err)
+		return false
+	}
+	if cmd.Type != battle.ENDPHASE {
+		g.MG.pc.SetPrevCursor(g.MG.pc.posXY)
+		g.MG.pc.posXY = g.MG.Battle.Units[cmd.UnitID].Pos()
+	}
+	return true
+}
+
+// Space pauses, N steps one command while paused, Tab toggles fast forward
+func (g *Game) UpdatePlayback() {
+	p := g.Playback
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		p.Paused = !p.Paused
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyTab) {
+		p.Fast = !p.Fast
+	}
+
+	step := false
+	if p.Paused {
+		step = inpututil.IsKeyJustPressed(ebiten.KeyN)
+	} else {
+		p.frames += 1
+		step = p.Fast || p.frames%playbackDelay == 0
+	}
+	if step && !g.Step() {
+		g.finishPlayback()
+	}
+}
+
+func (g *Game) finishPlayback() {
+	hash := g.StateHash()
+	if g.Playback.Next == len(g.Playback.Replay.Commands) && g.Playback.Replay.Migrated() {
+		Logger.Println("Replay finished, it was recorded by an older version so its state can't be checked")
+	} else if g.Playback.Next == len(g.Playback.Replay.Commands) && hash == g.Playback.Replay.Hash {
+		Logger.Println("Replay finished on the recorded state", hash)
+	} else {
+		Logger.Printf("Replay finished on state %s, expected %s", hash, g.Playback.Replay.Hash)
+	}
+	g.Playback = nil
+	g.StartRecording()
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"openFE/internal/battle"
+	"openFE/internal/rng"
+)
+
+// Player and enemy a few tiles apart on the real map, recording from the start
+func recordedGame(t *testing.T) *Game {
+	p := testUnit(0, battle.PLAYER, PosXY{0, 1}, 5)
+	e := testUnit(1, battle.ENEMY, PosXY{2, 3}, 5)
+	g := &Game{MG: testLevelMGrid(t, p, e), Rng: rng.New(42)}
+	g.StartRecording()
+	return g
+}
+
+func apply(t *testing.T, g *Game, commands ...battle.Command) {
+	for _, cmd := range commands {
+		_, err := g.Apply(cmd)
+		assert.NoError(t, err)
+	}
+}
+
+func TestReplayReachesSameState(t *testing.T) {
+	// Given a few rounds of fighting with an undone attack in the middle
+	g := recordedGame(t)
+	apply(t, g,
+		battle.Command{Type: battle.MOVE, UnitID: 0, To: PosXY{2, 2}},
+		battle.Command{Type: battle.ATTACK, UnitID: 0, TargetID: 1},
+	)
+	g.Undo()
+	apply(t, g,
+		battle.Command{Type: battle.MOVE, UnitID: 0, To: PosXY{1, 3}},
+		battle.Command{Type: battle.ATTACK, UnitID: 0, TargetID: 1},
+		battle.Command{Type: battle.ATTACK, UnitID: 1, TargetID: 0},
+	)
+	path := filepath.Join(t.TempDir(), "replay.json")
+
+	// When
+	assert.NoError(t, g.SaveReplay(path))
+	replay, err := battle.ReadReplay(path)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Len(t, replay.Commands, 3)
+	assert.Equal(t, g.StateHash(), replay.Hash)
+	assert.NoError(t, replay.Verify(LdtkProject))
+
+	// When played back step by step
+	playback := &Game{}
+	assert.NoError(t, playback.StartPlayback(replay))
+	for playback.Step() {
+	}
+
+	// Then
+	assert.Equal(t, replay.Hash, playback.StateHash())
+}
+
+func TestReplayVerifyCatchesDivergence(t *testing.T) {
+	// Given
+	g := recordedGame(t)
+	apply(t, g, battle.Command{Type: battle.MOVE, UnitID: 0, To: PosXY{1, 3}}, battle.Command{Type: battle.ATTACK, UnitID: 0, TargetID: 1})
+	path := filepath.Join(t.TempDir(), "replay.json")
+	assert.NoError(t, g.SaveReplay(path))
+	replay, _ := battle.ReadReplay(path)
+
+	// When the battle started with another seed
+	replay.Start.Rng += 1
+	err := replay.Verify(LdtkProject)
+
+	// Then
+	assert.Error(t, err)
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"openFE/internal/battle"
+	"openFE/internal/rng"
+)
+
+const (
+	SavesDir      = "saves"
+	QuickSaveSlot = 0
+)
+
+func SavePath(slot int) string {
+	return filepath.Join(SavesDir, fmt.Sprintf("slot%d.json", slot))
+}
+
+func (g *Game) SaveData() battle.SaveData {
+	var state uint64
+	if g.Rng != nil {
+		state = g.Rng.State()
+	}
+	return g.MG.Battle.SaveData(state)
+}
+
+// Save writes the battle to path, creating the directory if needed
+func (g *Game) Save(path string) error {
+	return g.SaveData().Write(path)
+}
+
+// Load replaces the battle with the one saved at path. History starts over
+// from the loaded state.
+func (g *Game) Load(path string) error {
+	data, err := battle.ReadSave(path)
+	if err != nil {
+		return err
+	}
+	b, err := data.Battle(LdtkProject)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	if g.Rng == nil {
+		g.Rng = rng.New(data.Rng)
+	}
+	g.Rng.SetState(data.Rng)
+	g.SetBattle(b)
+	return nil
+}
+
+// SetBattle swaps the battle being played, history and the replay start over from it
+func (g *Game) SetBattle(b *battle.Battle) {
+	g.MG = CreateMGrid(b, CursorSprite)
+	if g.Replay != nil {
+		g.StartRecording()
+	} else {
+		g.resetHistory()
+	}
+}
+
+// NextLevel carries the surviving player units over to the level after the
+// one being played
+func (g *Game) NextLevel() error {
+	next := battle.NextLevel(LdtkProject, g.MG.Battle.Level())
+	if next == "" {
+		Logger.Println("Last level cleared")
+		return nil
+	}
+	b, err := battle.NewLevel(LdtkProject, next, g.MG.Battle.Survivors())
+	if err != nil {
+		return err
+	}
+	Logger.Println("Starting", next)
+	g.SetBattle(b)
+	return nil
+}
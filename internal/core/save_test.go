@@ -0,0 +1,99 @@
+package core
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/solarlune/ldtkgo"
+	"github.com/stretchr/testify/assert"
+
+	"openFE/internal/battle"
+	"openFE/internal/rng"
+)
+
+func loadTestProject(t *testing.T) {
+	project, err := ldtkgo.Open(filepath.Join("..", "..", "assets", "demo", "8x8.ldtk"))
+	assert.NoError(t, err)
+	LdtkProject = project
+}
+
+// Grid on the demo map's first level with units instead of its spawns
+func testLevelMGrid(t *testing.T, units ...*battle.Unit) MGrid {
+	loadTestProject(t)
+	level := LdtkProject.Levels[0]
+	terrain, err := battle.LevelTerrain(level)
+	assert.NoError(t, err)
+	return CreateMGrid(battle.New(level.Identifier, terrain, units), nil)
+}
+
+func TestGameSaveLoad(t *testing.T) {
+	// Given a saved game
+	p := testUnit(0, battle.PLAYER, PosXY{0, 1}, 5)
+	e := testUnit(1, battle.ENEMY, PosXY{1, 0}, 5)
+	g := &Game{MG: testLevelMGrid(t, p, e), Rng: rng.New(7)}
+	g.Rng.Intn(100)
+	path := filepath.Join(t.TempDir(), "slot1.json")
+	assert.NoError(t, g.Save(path))
+	hash := g.StateHash()
+
+	// When the game goes on then loads the save back
+	_, err := g.Apply(battle.Command{Type: battle.MOVE, UnitID: p.ID(), To: PosXY{0, 2}})
+	assert.NoError(t, err)
+	assert.NoError(t, g.Load(path))
+
+	// Then
+	assert.Equal(t, hash, g.StateHash())
+	assert.Equal(t, PosXY{0, 1}, g.MG.Battle.Units[p.ID()].Pos())
+	assert.Equal(t, SELECTUNIT, g.MG.turnState)
+	assert.Len(t, g.History, 1)
+}
+
+func TestLoadMidActionOpensActionMenu(t *testing.T) {
+	// Given a game saved after a unit moved
+	p := testUnit(0, battle.PLAYER, PosXY{0, 1}, 5)
+	e := testUnit(1, battle.ENEMY, PosXY{3, 3}, 5)
+	g := &Game{MG: testLevelMGrid(t, p, e), Rng: rng.New(7)}
+	selectAndMove(t, g, p, PosXY{0, 2})
+	path := filepath.Join(t.TempDir(), "slot1.json")
+	assert.NoError(t, g.Save(path))
+
+	// When
+	assert.NoError(t, g.Load(path))
+	g.MenuManager.Sync(g)
+
+	// Then
+	assert.Equal(t, UNITACTIONS, g.MG.turnState)
+	assert.Equal(t, p.ID(), g.MG.selectedUnit)
+	assert.Equal(t, &g.MenuManager.ActionMenu, g.MenuManager.Top())
+}
+
+func TestNextLevelCarriesSurvivors(t *testing.T) {
+	// Given a project with a second level and a recorded game on the first
+	p := testUnit(0, battle.PLAYER, PosXY{0, 1}, 5)
+	e := testUnit(1, battle.ENEMY, PosXY{1, 0}, 5)
+	mg := testLevelMGrid(t, p, e)
+	second := *LdtkProject.Levels[0]
+	second.Identifier = "Level_1"
+	second.Layers = slices.DeleteFunc(slices.Clone(second.Layers), func(l *ldtkgo.Layer) bool { return l.Identifier == battle.EntitiesLayer })
+	LdtkProject.Levels = append(LdtkProject.Levels, &second)
+	g := &Game{MG: mg, Rng: rng.New(7)}
+	g.StartRecording()
+	g.MG.Battle.KillUnit(e)
+
+	// When
+	assert.NoError(t, g.NextLevel())
+
+	// Then
+	assert.Equal(t, "Level_1", g.MG.Battle.Level())
+	assert.Len(t, g.MG.Battle.Units, 1)
+	assert.Equal(t, PosXY{0, 1}, g.MG.Battle.Units[0].Pos())
+	assert.Equal(t, "Level_1", g.Replay.Start.Level)
+	assert.Len(t, g.History, 1)
+
+	// When the last level is cleared
+	assert.NoError(t, g.NextLevel())
+
+	// Then the game stays on it
+	assert.Equal(t, "Level_1", g.MG.Battle.Level())
+}
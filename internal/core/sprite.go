@@ -5,6 +5,8 @@ import (
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"golang.org/x/image/math/f64"
+
+	"openFE/internal/battle"
 )
 
 type SpriteCell struct {
@@ -34,55 +36,57 @@ type RenderData struct {
 	spritesheet *ebiten.Image
 }
 
-type PosXY [2]int
-
-type Unit struct {
-	id           int
-	posXYHistory []PosXY
-	posXY        PosXY
-	rpg          RPG
-	rd           RenderData // Note: Can be optional
-}
-
-func CreateUnit(id int, spritesheet *ebiten.Image, rpg RPG, posXY PosXY) Unit {
-	idleAnimData := AnimationData{SpriteCell{0, 0, 16, 16}, 4, 16}
-
-	GridCellStartingX0 := MapStartingX0 + float64(16*posXY[X])
-	GridCellStartingY0 := MapStartingY0 + float64(16*posXY[Y])
+type PosXY = battle.PosXY
 
-	rd := RenderData{
-		x0y0:        f64.Vec2{GridCellStartingX0, GridCellStartingY0},
-		ad:          idleAnimData,
-		spritesheet: spritesheet,
-	}
+// Every unit sheet has the idle cycle on its first row
+var unitAnimData = AnimationData{SpriteCell{0, 0, 16, 16}, 4, 16}
 
-	u := Unit{
-		id:           id,
-		posXYHistory: []PosXY{posXY},
-		posXY:        posXY,
-		rpg:          rpg,
-		rd:           rd,
+// Sheet of u's job, units whose job has no sprite use UnitSprite
+func unitSprite(u *battle.Unit) *ebiten.Image {
+	if sprite, ok := JobSprites[u.RPG().Job]; ok {
+		return sprite
 	}
-
-	return u
+	return UnitSprite
 }
 
-func (u *Unit) posXYAppendHistory(posXY PosXY) {
-	u.posXYHistory = append(u.posXYHistory, posXY)
+// IdleAnimation draws u standing on the cell at x0y0
+func IdleAnimation(screen *ebiten.Image, u *battle.Unit, x0y0 f64.Vec2, offsetX, offsetY float64, count int) {
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(float64(CAMERASCALE), float64(CAMERASCALE))
+	op.GeoM.Translate(x0y0[X]+offsetX, x0y0[Y]+offsetY)
+
+	ad := unitAnimData
+	spritesheet := unitSprite(u)
+	cellX := ad.sc.cellX
+	cellY := ad.sc.cellY
+
+	i := (count / ad.frameFrequency) % ad.frameCount
+	if u.Waited() {
+		// Greyed out and frozen on the first frame until the next phase
+		op.ColorScale.Scale(0.5, 0.5, 0.5, 1)
+		i = 0
+	}
+	sx, sy := ad.sc.GetCol(cellX)+i*ad.sc.frameWidth, ad.sc.GetRow(cellY)
+	screen.DrawImage(spritesheet.SubImage(image.Rect(sx, sy, sx+ad.sc.frameWidth, sy+ad.sc.frameHeight)).(*ebiten.Image), op)
 }
 
-func (u *Unit) IdleAnimation(screen *ebiten.Image, offsetX, offsetY float64, count int) {
+// Walk frames are expected on the rows under the idle row, in Direction order
+// (down, up, left, right). Sheets without them fall back to the idle row.
+func WalkAnimation(screen *ebiten.Image, u *battle.Unit, x0y0 f64.Vec2, offsetX, offsetY float64, count int, direction Direction) {
 	op := &ebiten.DrawImageOptions{}
 	op.GeoM.Scale(float64(CAMERASCALE), float64(CAMERASCALE))
-	// Note: might move render calculation to where it's being called
-	x0 := u.rd.x0y0[X]
-	y0 := u.rd.x0y0[Y]
-	op.GeoM.Translate(x0+offsetX, y0+offsetY)
-
-	cellX := u.rd.ad.sc.cellX
-	cellY := u.rd.ad.sc.cellY
+	op.GeoM.Translate(x0y0[X]+offsetX, x0y0[Y]+offsetY)
+
+	ad := unitAnimData
+	spritesheet := unitSprite(u)
+	cellX := ad.sc.cellX
+	cellY := ad.sc.cellY + 1 + int(direction)
+	if ad.sc.GetRow(cellY+1) > spritesheet.Bounds().Dy() {
+		cellY = ad.sc.cellY
+	}
 
-	i := (count / u.rd.ad.frameFrequency) % u.rd.ad.frameCount
-	sx, sy := u.rd.ad.sc.GetCol(cellX)+i*u.rd.ad.sc.frameWidth, u.rd.ad.sc.GetRow(cellY)
-	screen.DrawImage(u.rd.spritesheet.SubImage(image.Rect(sx, sy, sx+u.rd.ad.sc.frameWidth, sy+u.rd.ad.sc.frameHeight)).(*ebiten.Image), op)
+	// Walk cycles run twice as fast as idle
+	i := (count / max(1, ad.frameFrequency/2)) % ad.frameCount
+	sx, sy := ad.sc.GetCol(cellX)+i*ad.sc.frameWidth, ad.sc.GetRow(cellY)
+	screen.DrawImage(spritesheet.SubImage(image.Rect(sx, sy, sx+ad.sc.frameWidth, sy+ad.sc.frameHeight)).(*ebiten.Image), op)
 }
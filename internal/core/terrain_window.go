@@ -0,0 +1,45 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"openFE/internal/battle"
+)
+
+const (
+	terrainWindowWidth = 72
+	lineHeight         = 16
+)
+
+// Lines of the terrain window, healing only shows up on tiles that heal
+func TerrainInfo(t battle.Terrain) []string {
+	data := t.Data()
+	lines := []string{
+		data.Name,
+		fmt.Sprintf("DEF %d", data.Defense),
+		fmt.Sprintf("AVO %d", data.Avoid),
+	}
+	if data.Heal > 0 {
+		lines = append(lines, fmt.Sprintf("HEAL %d%%", data.Heal))
+	}
+	return lines
+}
+
+// Shows the effects of the tile under the cursor in the bottom left corner
+func (mg *MGrid) RenderTerrainWindow(screen *ebiten.Image) {
+	if !mg.Battle.InBounds(mg.pc.posXY) {
+		return
+	}
+	lines := TerrainInfo(mg.Battle.TerrainAt(mg.pc.posXY))
+	height := len(lines)*lineHeight + 8
+	x0 := 4
+	y0 := ScreenHeight - height - 4
+	vector.DrawFilledRect(screen, float32(x0), float32(y0), terrainWindowWidth, float32(height), windowColor, true)
+	for i, line := range lines {
+		ebitenutil.DebugPrintAt(screen, line, x0+4, y0+4+i*lineHeight)
+	}
+}
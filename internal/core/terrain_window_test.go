@@ -0,0 +1,27 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"openFE/internal/battle"
+)
+
+func TestTerrainInfo(t *testing.T) {
+	tests := []struct {
+		terrain  battle.Terrain
+		expected []string
+	}{
+		{battle.PLAINS, []string{"Plains", "DEF 0", "AVO 0"}},
+		{battle.FOREST, []string{"Forest", "DEF 1", "AVO 20"}},
+		{battle.FORT, []string{"Fort", "DEF 2", "AVO 20", "HEAL 20%"}},
+		{battle.THRONE, []string{"Throne", "DEF 3", "AVO 30", "HEAL 10%"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.terrain.Data().Name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, TerrainInfo(tt.terrain))
+		})
+	}
+}
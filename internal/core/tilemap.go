@@ -0,0 +1,121 @@
+package core
+
+import (
+	"encoding/json"
+	"image"
+	"path/filepath"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/solarlune/ldtkgo"
+)
+
+// Tile layers named with this prefix are drawn above units, e.g. tree canopies or roofs
+const OverlayPrefix = "Overlay"
+
+var (
+	TilesetSprites = map[int]*ebiten.Image{} // Keyed by ldtk tileset uid
+	layerOpacity   = map[layerKey]float64{}
+)
+
+type layerKey struct {
+	level string
+	layer string
+}
+
+// TileLayer is one ldtk tile or auto-layer ready to draw
+type TileLayer struct {
+	Identifier string
+	Tiles      []*ldtkgo.Tile
+	Tileset    *ebiten.Image
+	GridSize   int
+	OffsetX    float64
+	OffsetY    float64
+	Opacity    float64
+	Overlay    bool
+}
+
+// ldtkgo doesn't read layer opacity so it comes from the raw project file
+func readLayerOpacities(data []byte) (map[layerKey]float64, error) {
+	var raw struct {
+		Levels []struct {
+			Identifier     string `json:"identifier"`
+			LayerInstances []struct {
+				Identifier string  `json:"__identifier"`
+				Opacity    float64 `json:"__opacity"`
+			} `json:"layerInstances"`
+		} `json:"levels"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	opacity := map[layerKey]float64{}
+	for _, level := range raw.Levels {
+		for _, layer := range level.LayerInstances {
+			opacity[layerKey{level.Identifier, layer.Identifier}] = layer.Opacity
+		}
+	}
+	return opacity, nil
+}
+
+// Path of a tileset image, which ldtk stores relative to the project file
+func tilesetPath(projectPath string, tileset *ldtkgo.Tileset) string {
+	return filepath.Join(filepath.Dir(projectPath), tileset.Path)
+}
+
+// LevelTileLayers returns the visible layers with tiles, bottom to top.
+// ldtkgo keeps the ldtk order where the first layer is on top.
+func LevelTileLayers(level *ldtkgo.Level) []TileLayer {
+	layers := []TileLayer{}
+	for i := len(level.Layers) - 1; i >= 0; i-- {
+		layer := level.Layers[i]
+		tiles := layer.AllTiles()
+		if !layer.Visible || layer.Tileset == nil || len(tiles) == 0 {
+			continue
+		}
+		opacity, ok := layerOpacity[layerKey{level.Identifier, layer.Identifier}]
+		if !ok {
+			opacity = 1
+		}
+		layers = append(layers, TileLayer{
+			Identifier: layer.Identifier,
+			Tiles:      tiles,
+			Tileset:    TilesetSprites[layer.Tileset.ID],
+			GridSize:   layer.GridSize,
+			OffsetX:    float64(layer.OffsetX),
+			OffsetY:    float64(layer.OffsetY),
+			Opacity:    opacity,
+			Overlay:    strings.HasPrefix(layer.Identifier, OverlayPrefix),
+		})
+	}
+	return layers
+}
+
+func (tl *TileLayer) Draw(screen *ebiten.Image, cameraOffsetX, cameraOffsetY float64) {
+	if tl.Tileset == nil {
+		return
+	}
+	size := float64(tl.GridSize)
+	for _, tile := range tl.Tiles {
+		op := &ebiten.DrawImageOptions{}
+		// Flip around the tile's center before moving it into place
+		if tile.FlipX() {
+			op.GeoM.Scale(-1, 1)
+			op.GeoM.Translate(size, 0)
+		}
+		if tile.FlipY() {
+			op.GeoM.Scale(1, -1)
+			op.GeoM.Translate(0, size)
+		}
+
+		x0 := float64(tile.Position[0]) + tl.OffsetX
+		y0 := float64(tile.Position[1]) + tl.OffsetY
+		// No idea why I needed to divide by camerascale
+		// in order to fix zoomin zoomout when I didn't need to do that for unitsprite
+		op.GeoM.Translate(x0+cameraOffsetX/CAMERASCALE, y0+cameraOffsetY/CAMERASCALE)
+		op.GeoM.Scale(float64(CAMERASCALE), float64(CAMERASCALE))
+		op.ColorScale.ScaleAlpha(float32(tl.Opacity))
+		src := image.Rect(tile.Src[0], tile.Src[1], tile.Src[0]+tl.GridSize, tile.Src[1]+tl.GridSize)
+		screen.DrawImage(tl.Tileset.SubImage(src).(*ebiten.Image), op)
+	}
+}
@@ -0,0 +1,53 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/solarlune/ldtkgo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadLayerOpacities(t *testing.T) {
+	// Given
+	data, err := os.ReadFile(filepath.Join("..", "..", "assets", "demo", "8x8.ldtk"))
+	assert.NoError(t, err)
+
+	// When
+	opacity, err := readLayerOpacities(data)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, opacity[layerKey{"Level_0", "Tiles"}])
+	assert.Equal(t, 0.8, opacity[layerKey{"Level_0", "Overlay"}])
+}
+
+func TestLevelTileLayersBottomToTop(t *testing.T) {
+	// Given layers in ldtk order, top first
+	tileset := &ldtkgo.Tileset{ID: 3}
+	tile := []*ldtkgo.Tile{{Position: []int{0, 0}, Src: []int{0, 0}}}
+	level := &ldtkgo.Level{Identifier: "Test", Layers: []*ldtkgo.Layer{
+		{Identifier: "Overlay_Roofs", Visible: true, Tileset: tileset, Tiles: tile},
+		{Identifier: "Entities", Visible: true},
+		{Identifier: "Hidden", Visible: false, Tileset: tileset, Tiles: tile},
+		{Identifier: "Decor", Visible: true, Tileset: tileset, AutoTiles: tile, OffsetX: 4},
+		{Identifier: "Tiles", Visible: true, Tileset: tileset, Tiles: tile},
+	}}
+	layerOpacity = map[layerKey]float64{{"Test", "Decor"}: 0.5}
+	defer func() { layerOpacity = map[layerKey]float64{} }()
+
+	// When
+	layers := LevelTileLayers(level)
+
+	// Then
+	names := []string{}
+	for _, layer := range layers {
+		names = append(names, layer.Identifier)
+	}
+	assert.Equal(t, []string{"Tiles", "Decor", "Overlay_Roofs"}, names)
+	assert.Equal(t, 1.0, layers[0].Opacity)
+	assert.Equal(t, 0.5, layers[1].Opacity)
+	assert.Equal(t, 4.0, layers[1].OffsetX)
+	assert.Equal(t, []bool{false, false, true}, []bool{layers[0].Overlay, layers[1].Overlay, layers[2].Overlay})
+}
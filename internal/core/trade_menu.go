@@ -0,0 +1,143 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"openFE/internal/battle"
+)
+
+// TradeMenu swaps items with an adjacent ally. The arrows pick the ally first,
+// then an item on one side and one on the other side are swapped.
+type TradeMenu struct {
+	Partners []*battle.Unit
+	Partner  int  // Index of the ally being traded with
+	Picked   bool // The ally is picked, both inventories are open
+	Side     int  // 0 the selected unit's inventory, 1 the ally's
+	Slot     int  // Selected line of Side
+	Held     int  // Slot picked on HeldSide, -1 while nothing is held
+	HeldSide int
+}
+
+// Open starts on the first ally, the inventories open right away when there is only one
+func (m *TradeMenu) Open(g *Game, partners []*battle.Unit) {
+	*m = TradeMenu{Partners: partners, Picked: len(partners) == 1, Held: -1}
+	g.MG.pc.SetPrevCursor(g.MG.pc.posXY)
+	g.MG.pc.posXY = partners[0].Pos()
+}
+
+// tradeLines is how many slots of u's inventory can be picked, its items and
+// the free space after them
+func tradeLines(u *battle.Unit) int {
+	return min(len(u.Items())+1, battle.MaxItems)
+}
+
+// Pick holds the selected slot, or swaps it with the one held on the other
+// side. Returns the trade once both sides are picked.
+func (m *TradeMenu) Pick(u *battle.Unit) (battle.Command, bool) {
+	if m.Held == -1 || m.HeldSide == m.Side {
+		m.Held, m.HeldSide = m.Slot, m.Side
+		m.Side = 1 - m.Side
+		m.Slot = min(m.Slot, tradeLines(m.sideUnit(u))-1)
+		return battle.Command{}, false
+	}
+	mine, theirs := m.Held, m.Slot
+	if m.Side == 0 {
+		mine, theirs = theirs, mine
+	}
+	m.Held = -1
+	return battle.Command{Type: battle.TRADE, UnitID: u.ID(), TargetID: m.Partners[m.Partner].ID(), Item: mine, TargetItem: theirs}, true
+}
+
+// Unit whose inventory is on the selected side
+func (m *TradeMenu) sideUnit(u *battle.Unit) *battle.Unit {
+	if m.Side == 1 {
+		return m.Partners[m.Partner]
+	}
+	return u
+}
+
+func (m *TradeMenu) State() TurnState { return TRADEMENU }
+
+// Update cycles the allies until one is picked, then left and right switch
+// sides and up and down pick the slot
+func (m *TradeMenu) Update(g *Game, enter bool) {
+	u := g.MG.Battle.Units[g.MG.selectedUnit]
+	if !m.Picked {
+		if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) {
+			m.Partner = (m.Partner - 1 + len(m.Partners)) % len(m.Partners)
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) {
+			m.Partner = (m.Partner + 1) % len(m.Partners)
+		}
+		g.MG.pc.posXY = m.Partners[m.Partner].Pos()
+		m.Picked = enter
+		return
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) || inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) {
+		m.Side = 1 - m.Side
+	}
+	m.Slot = min(m.Slot, tradeLines(m.sideUnit(u))-1)
+	scrollList(&m.Slot, tradeLines(m.sideUnit(u)))
+	if !enter {
+		return
+	}
+	// Trading doesn't end the action, the menu stays open for the next swap
+	if cmd, ok := m.Pick(u); ok {
+		if _, err := g.Apply(cmd); err != nil {
+			Logger.Println(err)
+		}
+		m.Slot = min(m.Slot, tradeLines(m.sideUnit(u))-1)
+	}
+}
+
+// Cancel drops the held item, then goes back to picking the ally, then closes
+func (m *TradeMenu) Cancel(g *Game) bool {
+	switch {
+	case m.Held != -1:
+		m.Held = -1
+		return false
+	case m.Picked && len(m.Partners) > 1:
+		m.Picked = false
+		return false
+	}
+	g.MG.pc.posXY = g.MG.Battle.Units[g.MG.selectedUnit].Pos()
+	return true
+}
+
+func (m *TradeMenu) Draw(screen *ebiten.Image, g *Game) {
+	u := g.MG.Battle.Units[g.MG.selectedUnit]
+	partner := m.Partners[m.Partner]
+	if !m.Picked {
+		drawList(screen, 4, 4, itemMenuWidth, []string{"Trade with " + partner.Name()}, -1)
+		return
+	}
+	for side, owner := range []*battle.Unit{u, partner} {
+		x0 := 4 + side*(itemMenuWidth+2)
+		lines := []string{owner.Name()}
+		for slot := 0; slot < tradeLines(owner); slot++ {
+			lines = append(lines, m.slotLine(owner, side, slot))
+		}
+		selected := -1
+		if side == m.Side {
+			selected = m.Slot + 1 // The name comes first
+		}
+		drawList(screen, x0, 4, itemMenuWidth, lines, selected)
+	}
+}
+
+// A slot of owner's inventory, the held one is marked with a *
+func (m *TradeMenu) slotLine(owner *battle.Unit, side, slot int) string {
+	held := " "
+	if side == m.HeldSide && slot == m.Held {
+		held = "*"
+	}
+	items := owner.Items()
+	if slot >= len(items) {
+		return held + " -"
+	}
+	return fmt.Sprintf("%s %-12s %2d/%d", held, items[slot].Data().Name, items[slot].Uses, items[slot].Data().Uses)
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"openFE/internal/battle"
+)
+
+func TestTradeMenuSwapsItems(t *testing.T) {
+	// Given a unit that moved next to an ally
+	p := testUnit(0, battle.PLAYER, PosXY{0, 0}, 3)
+	ally := testUnit(1, battle.PLAYER, PosXY{2, 0}, 3)
+	e := testUnit(2, battle.ENEMY, PosXY{3, 1}, 3)
+	g := &Game{MG: testMGrid([]string{"....", "...."}, []*battle.Unit{p, ally, e}), History: []Snapshot{}}
+	g.Commit()
+	p.GiveItem("iron_lance")
+	ally.GiveItem("vulnerary")
+	selectAndMove(t, g, p, PosXY{1, 0})
+	mm := &g.MenuManager
+
+	mm.ActionMenu = CreateActionMenu(nil)
+	mm.ActionMenu.Selected = slices.Index(mm.ActionMenu.MenuOptions, "trade")
+
+	// When trade is picked and its lance is swapped for the ally's vulnerary
+	mm.Top().Update(g, true)
+	assert.Equal(t, Menu(&mm.TradeMenu), mm.Top())
+	_, ok := mm.TradeMenu.Pick(p)
+	assert.False(t, ok)
+	cmd, ok := mm.TradeMenu.Pick(p)
+	assert.True(t, ok)
+	_, err := g.Apply(cmd)
+
+	// Then the unit can still act from the trade menu
+	assert.NoError(t, err)
+	assert.Equal(t, []battle.Item{battle.NewItem("vulnerary")}, p.Items())
+	assert.Equal(t, []battle.Item{battle.NewItem("iron_lance")}, ally.Items())
+	assert.Equal(t, TRADEMENU, g.MG.turnState)
+	assert.Equal(t, PosXY{2, 0}, g.MG.pc.posXY)
+
+	// When
+	mm.Cancel(g)
+
+	// Then the cursor is back on the unit in the action menu
+	assert.Equal(t, Menu(&mm.ActionMenu), mm.Top())
+	assert.Equal(t, UNITACTIONS, g.MG.turnState)
+	assert.Equal(t, PosXY{1, 0}, g.MG.pc.posXY)
+}
+
+func TestTradeMenuCancelDropsHeldItemFirst(t *testing.T) {
+	// Given an item held
+	p := testUnit(0, battle.PLAYER, PosXY{0, 0}, 3)
+	ally := testUnit(1, battle.PLAYER, PosXY{1, 0}, 3)
+	g := &Game{MG: testMGrid([]string{"...."}, []*battle.Unit{p, ally})}
+	m := TradeMenu{}
+	m.Open(g, []*battle.Unit{ally})
+	m.Pick(p)
+
+	// When
+	closed := m.Cancel(g)
+
+	// Then
+	assert.False(t, closed)
+	assert.Equal(t, -1, m.Held)
+}
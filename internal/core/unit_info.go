@@ -0,0 +1,58 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"openFE/internal/battle"
+)
+
+const unitInfoWidth = 150
+
+// UnitInfoLines are the lines of u's stat sheet, its items come last
+func UnitInfoLines(u *battle.Unit) []string {
+	rpg := u.RPG()
+	s := rpg.Stats
+	lines := []string{
+		u.Name(),
+		fmt.Sprintf("%s Lv %d Exp %d", rpg.Job.Data().Name, rpg.Level, rpg.Exp),
+		fmt.Sprintf("HP  %2d/%d", rpg.HP, s.HP),
+		fmt.Sprintf("STR %2d  MAG %2d", s.Str, s.Mag),
+		fmt.Sprintf("SKL %2d  SPD %2d", s.Skl, s.Spd),
+		fmt.Sprintf("LCK %2d  DEF %2d", s.Lck, s.Def),
+		fmt.Sprintf("RES %2d  CON %2d", s.Res, s.Con),
+		fmt.Sprintf("MOV %2d", rpg.Move()),
+	}
+	for _, item := range u.Items() {
+		lines = append(lines, fmt.Sprintf("%-12s %2d/%d", item.Data().Name, item.Uses, item.Data().Uses))
+	}
+	return lines
+}
+
+// UnitInfo shows the stat sheet of any unit, I opens it on the unit under the cursor
+type UnitInfo struct {
+	UnitID int
+}
+
+// Open shows u's stat sheet
+func (m *UnitInfo) Open(u *battle.Unit) {
+	m.UnitID = u.ID()
+}
+
+func (m *UnitInfo) State() TurnState { return UNITINFO }
+
+// Update closes the sheet on enter, it only shows information
+func (m *UnitInfo) Update(g *Game, enter bool) {
+	if enter {
+		g.MenuManager.Pop(g)
+	}
+}
+
+func (m *UnitInfo) Cancel(g *Game) bool {
+	return true
+}
+
+func (m *UnitInfo) Draw(screen *ebiten.Image, g *Game) {
+	drawList(screen, 4, 4, unitInfoWidth, UnitInfoLines(g.MG.Battle.Units[m.UnitID]), -1)
+}
@@ -0,0 +1,23 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"openFE/internal/battle"
+)
+
+func TestUnitInfoLines(t *testing.T) {
+	// Given
+	u := testUnit(0, battle.PLAYER, PosXY{0, 0}, 5)
+	u.GiveItem("vulnerary")
+
+	// When
+	sut := UnitInfoLines(u)
+
+	// Then the stats come before the items
+	assert.Equal(t, "Test", sut[0])
+	assert.Equal(t, "MOV  5", sut[7])
+	assert.Equal(t, "Vulnerary     3/3", sut[8])
+}
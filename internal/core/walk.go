@@ -0,0 +1,150 @@
+package core
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/math/f64"
+
+	"openFE/internal/battle"
+	"openFE/internal/pathfind"
+)
+
+const walkFramesPerTile = 8
+
+type Direction int
+
+const (
+	DOWN Direction = iota
+	UP
+	LEFT
+	RIGHT
+)
+
+func directionOf(from, to PosXY) Direction {
+	switch {
+	case to[X] < from[X]:
+		return LEFT
+	case to[X] > from[X]:
+		return RIGHT
+	case to[Y] < from[Y]:
+		return UP
+	}
+	return DOWN
+}
+
+// SelectForMove keeps the move tree of u around so the arrow can follow the cursor
+func (mg *MGrid) SelectForMove(u *battle.Unit) {
+	tree, legalPositions := mg.Battle.Moves(u)
+	mg.moveTree = tree
+	mg.legalPositions = legalPositions
+	mg.attackPositions = mg.Battle.AttackRange(u, legalPositions)
+	mg.path = []PosXY{u.Pos()}
+}
+
+// CancelSelect drops the unit picked for a move, the cursor goes back onto it
+func (mg *MGrid) CancelSelect() {
+	mg.pc.posXY = mg.Battle.Units[mg.selectedUnit].Pos()
+	mg.pc.SetColor(GREEN)
+	mg.ClearSelectedUnit()
+	mg.path = []PosXY{}
+	mg.SetState(SELECTUNIT)
+}
+
+// SteerPath updates the arrow after the cursor moved onto cursor
+func (mg *MGrid) SteerPath(u *battle.Unit, cursor PosXY) {
+	path := make([][2]int, len(mg.path))
+	for i, p := range mg.path {
+		path[i] = p
+	}
+	rpg := u.RPG()
+	path = pathfind.Steer(path, cursor, mg.moveTree, rpg.Move(), mg.Battle.TerrainCost(u))
+
+	mg.path = make([]PosXY, len(path))
+	for i, p := range path {
+		mg.path[i] = p
+	}
+}
+
+// Walk animates a unit along a path. The battle already moved the unit, only
+// its sprite is drawn along the way until it arrives.
+type Walk struct {
+	unit  *battle.Unit
+	path  []PosXY
+	frame int
+}
+
+func (mg *MGrid) StartWalk(u *battle.Unit) {
+	mg.walk = &Walk{unit: u, path: mg.path}
+	mg.SetState(UNITWALK)
+}
+
+// UpdateWalk advances the walk by a frame, returns true once the unit has arrived
+func (mg *MGrid) UpdateWalk() bool {
+	if mg.walk == nil {
+		return true
+	}
+	mg.walk.frame += 1
+	if mg.walk.frame < (len(mg.walk.path)-1)*walkFramesPerTile {
+		return false
+	}
+	mg.walk = nil
+	return true
+}
+
+// Screen position between the two tiles the unit is walking between
+func (w *Walk) Position(mg *MGrid) (f64.Vec2, Direction) {
+	if len(w.path) < 2 {
+		p := w.path[0]
+		return mg.grid[p[Y]][p[X]].x0y0, DOWN
+	}
+	step := min(w.frame/walkFramesPerTile, len(w.path)-2)
+	from, to := w.path[step], w.path[step+1]
+	t := float64(w.frame-step*walkFramesPerTile) / walkFramesPerTile
+	a := mg.grid[from[Y]][from[X]].x0y0
+	b := mg.grid[to[Y]][to[X]].x0y0
+	return f64.Vec2{a[X] + (b[X]-a[X])*t, a[Y] + (b[Y]-a[Y])*t}, directionOf(from, to)
+}
+
+func (mg *MGrid) RenderPath(screen *ebiten.Image, offsetX, offsetY float64, count int) {
+	if len(mg.path) < 2 {
+		return
+	}
+	f32cameraScale := float32(CAMERASCALE)
+	half := 8 * f32cameraScale
+	center := func(p PosXY) (float32, float32) {
+		x0y0 := mg.grid[p[Y]][p[X]].x0y0
+		return float32(x0y0[X]+offsetX) + half, float32(x0y0[Y]+offsetY) + half
+	}
+
+	arrowColor := color.RGBA{R: 255, G: 200, B: 0, A: 255}
+	width := 3 * f32cameraScale
+	for i := 0; i < len(mg.path)-1; i++ {
+		x0, y0 := center(mg.path[i])
+		x1, y1 := center(mg.path[i+1])
+		vector.StrokeLine(screen, x0, y0, x1, y1, width, arrowColor, true)
+	}
+
+	// Arrow head pointing the way the last step goes
+	end := mg.path[len(mg.path)-1]
+	x, y := center(end)
+	size := 5 * f32cameraScale
+	var lx, ly, rx, ry float32
+	switch directionOf(mg.path[len(mg.path)-2], end) {
+	case UP:
+		lx, ly, rx, ry = x-size, y, x+size, y
+		y -= size
+	case DOWN:
+		lx, ly, rx, ry = x-size, y, x+size, y
+		y += size
+	case LEFT:
+		lx, ly, rx, ry = x, y-size, x, y+size
+		x -= size
+	case RIGHT:
+		lx, ly, rx, ry = x, y-size, x, y+size
+		x += size
+	}
+	vector.StrokeLine(screen, lx, ly, x, y, width, arrowColor, true)
+	vector.StrokeLine(screen, rx, ry, x, y, width, arrowColor, true)
+}
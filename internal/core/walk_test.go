@@ -0,0 +1,87 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"openFE/internal/battle"
+)
+
+func TestSteerAndWalkPath(t *testing.T) {
+	// Given
+	u := testUnit(0, battle.PLAYER, PosXY{0, 0}, 3)
+	mg := testMGrid([]string{
+		"...",
+		"...",
+	}, []*battle.Unit{u})
+	mg.SetSelectedUnit(u.ID())
+	mg.SelectForMove(u)
+
+	// When the cursor goes down then right
+	mg.SteerPath(u, PosXY{0, 1})
+	mg.SteerPath(u, PosXY{1, 1})
+
+	// Then
+	assert.Equal(t, []PosXY{{0, 0}, {0, 1}, {1, 1}}, mg.path)
+
+	// When
+	mg.StartWalk(u)
+	frames := 1
+	for !mg.UpdateWalk() {
+		frames += 1
+	}
+
+	// Then
+	assert.Equal(t, 2*walkFramesPerTile, frames)
+	assert.Nil(t, mg.walk)
+	assert.Equal(t, UNITWALK, mg.turnState)
+}
+
+func TestWalkPositionDirection(t *testing.T) {
+	// Given
+	u := testUnit(0, battle.PLAYER, PosXY{0, 0}, 3)
+	mg := testMGrid([]string{"...."}, []*battle.Unit{u})
+	SetGridCellCoord(&mg, 0, 0)
+	w := &Walk{unit: u, path: []PosXY{{0, 0}, {1, 0}}, frame: walkFramesPerTile / 2}
+
+	// When
+	x0y0, direction := w.Position(&mg)
+
+	// Then
+	assert.Equal(t, RIGHT, direction)
+	assert.Equal(t, mg.grid[0][1].x0y0[X]/2, x0y0[X])
+}
+
+func TestStayingPutKeepsActions(t *testing.T) {
+	// Given
+	g, p, _ := testGame()
+	_, err := g.Apply(battle.Command{Type: battle.SELECT, UnitID: p.ID()})
+	assert.NoError(t, err)
+
+	// When the unit "moves" onto its own tile
+	assert.Contains(t, g.MG.legalPositions, p.Pos())
+	_, err = g.Apply(battle.Command{Type: battle.MOVE, UnitID: p.ID(), To: p.Pos()})
+
+	// Then it can still act
+	assert.NoError(t, err)
+	assert.Equal(t, UNITACTIONS, g.MG.turnState)
+	assert.False(t, g.MG.Battle.Units[p.ID()].Waited())
+}
+
+func TestCancelSelect(t *testing.T) {
+	// Given a unit picked for a move with the cursor elsewhere
+	g, p, _ := testGame()
+	_, err := g.Apply(battle.Command{Type: battle.SELECT, UnitID: p.ID()})
+	assert.NoError(t, err)
+	g.MG.pc.posXY = PosXY{2, 1}
+
+	// When
+	g.MG.CancelSelect()
+
+	// Then
+	assert.Equal(t, SELECTUNIT, g.MG.turnState)
+	assert.Equal(t, notSelected, g.MG.selectedUnit)
+	assert.Equal(t, p.Pos(), g.MG.pc.posXY)
+	assert.Nil(t, g.MG.Battle.MidAction())
+}
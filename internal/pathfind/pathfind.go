@@ -0,0 +1,224 @@
+// Package pathfind finds every tile a unit can reach with a movement budget
+// over terrain with different costs.
+package pathfind
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// Cost returns what it costs to step onto pos, anything below 0 can't be entered
+type Cost func(pos [2]int) int
+
+// Who is standing on a tile, seen from the unit that is moving
+type Occupant int
+
+const (
+	EMPTY Occupant = iota
+	SELF
+	ALLY
+	ENEMY
+)
+
+type Tree struct {
+	Start  [2]int
+	Cost   map[[2]int]int    // Cheapest cost to reach each tile
+	Parent map[[2]int][2]int // Previous tile on the cheapest path, start has none
+}
+
+var directions = [][2]int{
+	{0, -1}, // Up
+	{0, 1},  // Down
+	{-1, 0}, // Left
+	{1, 0},  // Right
+}
+
+// Reachable runs dijkstra from start over a width x height grid and keeps every
+// tile whose cheapest path costs at most budget
+func Reachable(start [2]int, width, height, budget int, cost Cost) Tree {
+	tree := Tree{
+		Start:  start,
+		Cost:   map[[2]int]int{start: 0},
+		Parent: map[[2]int][2]int{},
+	}
+
+	pq := &queue{{pos: start, cost: 0}}
+	for pq.Len() > 0 {
+		current := heap.Pop(pq).(item)
+		if current.cost > tree.Cost[current.pos] {
+			continue // Stale entry, a cheaper path was already found
+		}
+
+		for _, d := range directions {
+			next := [2]int{current.pos[0] + d[0], current.pos[1] + d[1]}
+			if next[0] < 0 || next[1] < 0 || next[0] >= width || next[1] >= height {
+				continue
+			}
+			step := cost(next)
+			if step < 0 {
+				continue
+			}
+			total := current.cost + step
+			if total > budget {
+				continue
+			}
+			if known, ok := tree.Cost[next]; ok && known <= total {
+				continue
+			}
+			tree.Cost[next] = total
+			tree.Parent[next] = current.pos
+			heap.Push(pq, item{pos: next, cost: total})
+		}
+	}
+	return tree
+}
+
+// Moves applies the unit rules on top of Reachable: allies can be walked through
+// but not stopped on, enemies block the path entirely. Returns the tree and the
+// tiles the unit can end its move on.
+func Moves(start [2]int, width, height, budget int, terrain Cost, occupant func(pos [2]int) Occupant) (Tree, [][2]int) {
+	cost := func(pos [2]int) int {
+		if occupant(pos) == ENEMY {
+			return -1
+		}
+		return terrain(pos)
+	}
+	tree := Reachable(start, width, height, budget, cost)
+
+	destinations := [][2]int{}
+	for _, pos := range tree.Positions() {
+		if o := occupant(pos); o == EMPTY || o == SELF {
+			destinations = append(destinations, pos)
+		}
+	}
+	return tree, destinations
+}
+
+// AttackRange returns every tile between minRange and maxRange (manhattan) of
+// any of the from tiles, leaving out the from tiles themselves. Sorted by row then column.
+func AttackRange(from [][2]int, minRange, maxRange, width, height int) [][2]int {
+	standing := map[[2]int]bool{}
+	for _, pos := range from {
+		standing[pos] = true
+	}
+
+	seen := map[[2]int]bool{}
+	tiles := [][2]int{}
+	for _, pos := range from {
+		for dy := -maxRange; dy <= maxRange; dy++ {
+			for dx := -maxRange; dx <= maxRange; dx++ {
+				d := abs(dx) + abs(dy)
+				if d < minRange || d > maxRange {
+					continue
+				}
+				t := [2]int{pos[0] + dx, pos[1] + dy}
+				if t[0] < 0 || t[1] < 0 || t[0] >= width || t[1] >= height || standing[t] || seen[t] {
+					continue
+				}
+				seen[t] = true
+				tiles = append(tiles, t)
+			}
+		}
+	}
+	sort.Slice(tiles, func(i, j int) bool {
+		if tiles[i][1] != tiles[j][1] {
+			return tiles[i][1] < tiles[j][1]
+		}
+		return tiles[i][0] < tiles[j][0]
+	})
+	return tiles
+}
+
+// Steer updates an arrow path when the cursor moves onto to. Going back over the
+// path cuts it there, stepping next to the end extends it as long as it stays
+// within budget, anything else falls back to the cheapest path. Tiles outside
+// the tree leave the path as it is.
+func Steer(path [][2]int, to [2]int, tree Tree, budget int, cost Cost) [][2]int {
+	if _, ok := tree.Cost[to]; !ok {
+		return path
+	}
+	for i, p := range path {
+		if p == to {
+			return path[:i+1]
+		}
+	}
+
+	if len(path) > 0 {
+		end := path[len(path)-1]
+		if abs(end[0]-to[0])+abs(end[1]-to[1]) == 1 {
+			total := 0
+			for _, p := range path[1:] {
+				total += cost(p)
+			}
+			if total+cost(to) <= budget {
+				return append(path[:len(path):len(path)], to)
+			}
+		}
+	}
+	return tree.Path(to)
+}
+
+// Positions returns every reachable tile sorted by cost, then row, then column
+func (t Tree) Positions() [][2]int {
+	positions := make([][2]int, 0, len(t.Cost))
+	for pos := range t.Cost {
+		positions = append(positions, pos)
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		a, b := positions[i], positions[j]
+		if t.Cost[a] != t.Cost[b] {
+			return t.Cost[a] < t.Cost[b]
+		}
+		if a[1] != b[1] {
+			return a[1] < b[1]
+		}
+		return a[0] < b[0]
+	})
+	return positions
+}
+
+// Path returns the tiles from start to end (both included), nil if end isn't reachable
+func (t Tree) Path(end [2]int) [][2]int {
+	if _, ok := t.Cost[end]; !ok {
+		return nil
+	}
+	path := [][2]int{end}
+	for end != t.Start {
+		end = t.Parent[end]
+		path = append(path, end)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+type item struct {
+	pos  [2]int
+	cost int
+}
+
+type queue []item
+
+func (q queue) Len() int { return len(q) }
+func (q queue) Less(i, j int) bool {
+	return q[i].cost < q[j].cost
+}
+func (q queue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *queue) Push(x any) {
+	*q = append(*q, x.(item))
+}
+func (q *queue) Pop() any {
+	old := *q
+	n := len(old)
+	it := old[n-1]
+	*q = old[:n-1]
+	return it
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,151 @@
+package pathfind
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Each rune is a tile cost, # can't be entered
+func costFrom(rows []string) Cost {
+	return func(pos [2]int) int {
+		c := rows[pos[1]][pos[0]]
+		if c == '#' {
+			return -1
+		}
+		return int(c - '0')
+	}
+}
+
+func TestReachableUniformCost(t *testing.T) {
+	// Given
+	rows := []string{
+		"111",
+		"111",
+		"111",
+	}
+
+	// When
+	sut := Reachable([2]int{1, 1}, 3, 3, 1, costFrom(rows))
+
+	// Then
+	assert.Equal(t, [][2]int{{1, 1}, {1, 0}, {0, 1}, {2, 1}, {1, 2}}, sut.Positions())
+}
+
+func TestReachableMixedTerrain(t *testing.T) {
+	// Given
+	rows := []string{
+		"1311",
+		"1#21",
+		"1111",
+	}
+
+	// When
+	sut := Reachable([2]int{0, 0}, 4, 3, 4, costFrom(rows))
+
+	// Then
+	assert.Equal(t, map[[2]int]int{
+		{0, 0}: 0,
+		{1, 0}: 3, {2, 0}: 4,
+		{0, 1}: 1,
+		{0, 2}: 2, {1, 2}: 3, {2, 2}: 4,
+	}, sut.Cost)
+}
+
+func TestReachablePrefersCheaperLongerPath(t *testing.T) {
+	// Given
+	rows := []string{
+		"191",
+		"111",
+	}
+
+	// When
+	sut := Reachable([2]int{0, 0}, 3, 2, 4, costFrom(rows))
+
+	// Then
+	assert.Equal(t, 4, sut.Cost[[2]int{2, 0}])
+	assert.Equal(t, [][2]int{{0, 0}, {0, 1}, {1, 1}, {2, 1}, {2, 0}}, sut.Path([2]int{2, 0}))
+}
+
+func TestReachableBlocked(t *testing.T) {
+	// Given
+	rows := []string{
+		"1#1",
+		"##1",
+	}
+
+	// When
+	sut := Reachable([2]int{0, 0}, 3, 2, 10, costFrom(rows))
+
+	// Then
+	assert.Equal(t, [][2]int{{0, 0}}, sut.Positions())
+	assert.Nil(t, sut.Path([2]int{2, 0}))
+}
+
+func TestMovesPassAlliesBlockEnemies(t *testing.T) {
+	// Given
+	rows := []string{
+		"111",
+		"111",
+	}
+	occupants := map[[2]int]Occupant{{0, 0}: SELF, {1, 0}: ALLY, {0, 1}: ENEMY}
+	occupant := func(pos [2]int) Occupant { return occupants[pos] }
+
+	// When
+	tree, sut := Moves([2]int{0, 0}, 3, 2, 2, costFrom(rows), occupant)
+
+	// Then
+	assert.Equal(t, [][2]int{{0, 0}, {2, 0}, {1, 1}}, sut)
+	assert.Equal(t, [][2]int{{0, 0}, {1, 0}, {2, 0}}, tree.Path([2]int{2, 0}))
+}
+
+func TestAttackRange(t *testing.T) {
+	// Given
+	from := [][2]int{{0, 0}, {1, 0}}
+
+	// When
+	melee := AttackRange(from, 1, 1, 3, 3)
+	bow := AttackRange([][2]int{{1, 1}}, 2, 2, 3, 3)
+
+	// Then
+	assert.Equal(t, [][2]int{{2, 0}, {0, 1}, {1, 1}}, melee)
+	assert.Equal(t, [][2]int{{0, 0}, {2, 0}, {0, 2}, {2, 2}}, bow)
+}
+
+func TestSteer(t *testing.T) {
+	// Given
+	rows := []string{
+		"1111",
+		"1111",
+	}
+	cost := costFrom(rows)
+	tree := Reachable([2]int{0, 0}, 4, 2, 3, cost)
+	path := [][2]int{{0, 0}}
+
+	// When the cursor wanders down, right, right
+	path = Steer(path, [2]int{0, 1}, tree, 3, cost)
+	path = Steer(path, [2]int{1, 1}, tree, 3, cost)
+	path = Steer(path, [2]int{2, 1}, tree, 3, cost)
+
+	// Then the arrow follows it instead of the cheapest path
+	assert.Equal(t, [][2]int{{0, 0}, {0, 1}, {1, 1}, {2, 1}}, path)
+
+	// When it goes back over the path
+	path = Steer(path, [2]int{1, 1}, tree, 3, cost)
+
+	// Then it is cut there
+	assert.Equal(t, [][2]int{{0, 0}, {0, 1}, {1, 1}}, path)
+
+	// When extending would go over budget
+	path = Steer(path, [2]int{1, 0}, tree, 3, cost)
+	path = Steer(path, [2]int{2, 0}, tree, 3, cost)
+
+	// Then it falls back to the cheapest path
+	assert.Equal(t, [][2]int{{0, 0}, {1, 0}, {2, 0}}, path)
+
+	// When the cursor leaves the move range
+	sut := Steer(path, [2]int{3, 1}, tree, 3, cost)
+
+	// Then nothing changes
+	assert.Equal(t, path, sut)
+}
@@ -0,0 +1,36 @@
+// Package rng is a small seedable random number generator. Its whole state is
+// a single integer so a battle can be reproduced (and later saved) exactly.
+package rng
+
+// RNG is a splitmix64 generator.
+type RNG struct {
+	state uint64
+}
+
+func New(seed uint64) *RNG {
+	return &RNG{state: seed}
+}
+
+func (r *RNG) next() uint64 {
+	r.state += 0x9e3779b97f4a7c15
+	z := r.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// Intn returns a number in [0, n). Panics if n <= 0, same as math/rand.
+func (r *RNG) Intn(n int) int {
+	if n <= 0 {
+		panic("rng: invalid argument to Intn")
+	}
+	return int(r.next() % uint64(n))
+}
+
+func (r *RNG) State() uint64 {
+	return r.state
+}
+
+func (r *RNG) SetState(state uint64) {
+	r.state = state
+}
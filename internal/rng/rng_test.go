@@ -0,0 +1,40 @@
+package rng
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSameSeedSameSequence(t *testing.T) {
+	// Given
+	a := New(42)
+	b := New(42)
+
+	// When / Then
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, a.Intn(100), b.Intn(100))
+	}
+}
+
+func TestSetStateResumesSequence(t *testing.T) {
+	// Given
+	r := New(7)
+	r.Intn(100)
+	state := r.State()
+	expected := []int{r.Intn(100), r.Intn(100), r.Intn(100)}
+
+	// When
+	r.SetState(state)
+
+	// Then
+	assert.Equal(t, expected, []int{r.Intn(100), r.Intn(100), r.Intn(100)})
+}
+
+func TestIntnRange(t *testing.T) {
+	r := New(1)
+	for i := 0; i < 1000; i++ {
+		n := r.Intn(10)
+		assert.True(t, n >= 0 && n < 10)
+	}
+}
@@ -1,13 +1,18 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	_ "image/png"
 	"log" // Adjust based on where these are defined
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 
 	// Import your internal package
+	"openFE/internal/battle"
 	core "openFE/internal/core" // Use alias to avoid conflict
+	"openFE/internal/rng"
 )
 
 var (
@@ -17,32 +22,51 @@ var (
 func init() {
 	core.LoadSpritesheets()
 
-	unitInfo := core.RPG{Job: core.NOBLE, Movement: 2}
-	u := core.CreateUnit(0, core.UnitSprite, unitInfo, core.PosXY{0, 1})
-	i := core.CreateUnit(1, core.UnitSprite, unitInfo, core.PosXY{1, 0})
-
-	units := []core.Unit{u, i}
-	unitPointers := make([]*core.Unit, len(units))
-	for i := range units {
-		unitPointers[i] = &units[i]
-	}
-	mgrid := core.CreateMGrid(unitPointers, core.CursorSprite, core.LdtkProject)
-
 	actionMenu := core.CreateActionMenu(core.ActionMenuSprite)
 	menuManager := core.MenuManager{ActionMenu: actionMenu}
 
 	game = &core.Game{
 		Camera:      core.Camera{X: 0, Y: 0},
-		MG:          mgrid,
-		History:     []core.MGrid{},
+		History:     []core.Snapshot{},
 		MenuManager: menuManager,
+		Rng:         rng.New(uint64(time.Now().UnixNano())),
 	}
-
-	game.AppendHistory(game.MG)
-	game.IncrementActionCounter()
 }
 
 func main() {
+	replayPath := flag.String("replay", "", "play back a replay file")
+	verify := flag.Bool("verify", false, "with -replay, re-simulate the replay without a window and check its final state hash")
+	level := flag.String("level", core.LdtkProject.Levels[0].Identifier, "identifier of the ldtk level to start on")
+	flag.Parse()
+
+	b, err := battle.NewLevel(core.LdtkProject, *level, []*battle.Unit{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	game.MG = core.CreateMGrid(b, core.CursorSprite)
+	game.StartRecording()
+
+	if *replayPath != "" {
+		replay, err := battle.ReadReplay(*replayPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if *verify {
+			if err := replay.Verify(core.LdtkProject); err != nil {
+				log.Fatal(err)
+			}
+			if replay.Migrated() {
+				fmt.Println("Replay plays through, it was recorded by an older version so its state isn't checked")
+			} else {
+				fmt.Println("Replay reaches state", replay.Hash)
+			}
+			return
+		}
+		if err := game.StartPlayback(replay); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	ebiten.SetWindowSize(core.ScreenWidth*2, core.ScreenHeight*2)
 	ebiten.SetWindowTitle("Platformer")
 	// ebiten.SetFullscreen(true)